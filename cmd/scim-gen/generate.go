@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/PennState/scim-client/pkg/scim"
+)
+
+// commentWidth is the column at which generated doc comments are wrapped.
+const commentWidth = 72
+
+// generator writes the Go types for a set of SCIM schemas.  Schemas that
+// are the core schema of a resource type are generated as structs that
+// implement scim.Resource, all others as structs that implement
+// scim.Extension.
+type generator struct {
+	pkg           string
+	resourceTypes []scim.ResourceType
+	consts        map[string]string // lower-cased URN -> name of the generated constant
+	names         map[string]bool   // names of the generated types
+	imports       map[string]bool
+	buf           bytes.Buffer
+}
+
+// generate returns the formatted Go source, in the provided package,
+// containing a type for each of the schemas.  The resource types are used
+// to determine which schemas are resources (rather than extensions) and to
+// generate their ResourceType variables - schemas whose URN doesn't
+// contain ":extension:" are considered to be resources if no resource type
+// refers to them.
+func generate(pkg string, schemas []scim.Schema, resourceTypes []scim.ResourceType) ([]byte, error) {
+	g := generator{
+		pkg:           pkg,
+		resourceTypes: resourceTypes,
+		consts:        map[string]string{},
+		names:         map[string]bool{},
+		imports:       map[string]bool{},
+	}
+	types := make([]string, len(schemas))
+	for idx, s := range schemas {
+		types[idx] = g.name(typeName(s))
+		g.consts[strings.ToLower(s.ID)] = types[idx] + "URN"
+	}
+	for idx, s := range schemas {
+		if rt, ok := g.resourceType(s, types[idx]); ok {
+			g.resource(s, types[idx], rt)
+			continue
+		}
+		g.extension(s, types[idx])
+	}
+
+	src := bytes.Buffer{}
+	src.WriteString("// Code generated by scim-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", g.pkg)
+	if len(g.imports) > 0 {
+		// Standard library packages are grouped before the others.
+		imports := make([]string, 0, len(g.imports))
+		for i := range g.imports {
+			imports = append(imports, i)
+		}
+		sort.Slice(imports, func(i, j int) bool {
+			si, sj := !strings.Contains(imports[i], "."), !strings.Contains(imports[j], ".")
+			if si != sj {
+				return si
+			}
+			return imports[i] < imports[j]
+		})
+		src.WriteString("import (\n")
+		for idx, i := range imports {
+			if idx > 0 && !strings.Contains(imports[idx-1], ".") && strings.Contains(i, ".") {
+				src.WriteString("\n")
+			}
+			fmt.Fprintf(&src, "\t%q\n", i)
+		}
+		src.WriteString(")\n\n")
+	}
+	src.Write(g.buf.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// resourceType returns the resource type of the schema and whether the
+// schema is the core schema of a resource (rather than an extension).
+func (g *generator) resourceType(s scim.Schema, name string) (scim.ResourceType, bool) {
+	extension := strings.Contains(strings.ToLower(s.ID), ":extension:")
+	for _, rt := range g.resourceTypes {
+		if strings.EqualFold(rt.Schema, s.ID) {
+			return rt, true
+		}
+		for _, se := range rt.SchemaExtensions {
+			if strings.EqualFold(se.Schema, s.ID) {
+				extension = true
+			}
+		}
+	}
+	if extension {
+		return scim.ResourceType{}, false
+	}
+	return scim.ResourceType{
+		Name:     name,
+		Endpoint: "/" + name + "s",
+		Schema:   s.ID,
+	}, true
+}
+
+func (g *generator) resource(s scim.Schema, name string, rt scim.ResourceType) {
+	g.imports["github.com/PennState/additional-properties/pkg/ap"] = true
+	g.imports["github.com/PennState/scim-client/pkg/scim"] = true
+	recv := receiver(name)
+	alias := strings.ToLower(name[:1]) + name[1:] + "Alias"
+
+	g.comment(fmt.Sprintf("%s is the SCIM resource defined by the %s schema.", name, s.ID), s.Description)
+	nested := g.structure(name, s.Attributes, true, true)
+	g.urn(name, s)
+
+	id := rt.ID
+	if id == "" {
+		id = rt.Name
+	}
+	g.comment(fmt.Sprintf("%sResourceType provides the default structure which connects the %s struct to its associated ResourceType.", name, name))
+	fmt.Fprintf(&g.buf, "var %sResourceType = scim.ResourceType{\n", name)
+	fmt.Fprintf(&g.buf, "CommonAttributes: scim.CommonAttributes{\nSchemas: []string{\nscim.ResourceTypeURN,\n},\nID: %q,\n},\n", id)
+	fmt.Fprintf(&g.buf, "Name: %q,\nEndpoint: %q,\n", rt.Name, rt.Endpoint)
+	if rt.Description != "" {
+		fmt.Fprintf(&g.buf, "Description: %q,\n", rt.Description)
+	}
+	fmt.Fprintf(&g.buf, "Schema: %sURN,\n", name)
+	if len(rt.SchemaExtensions) > 0 {
+		g.buf.WriteString("SchemaExtensions: []scim.SchemaExtension{\n")
+		for _, se := range rt.SchemaExtensions {
+			fmt.Fprintf(&g.buf, "{Schema: %s", g.urnExpr(se.Schema))
+			if se.Required {
+				g.buf.WriteString(", Required: true")
+			}
+			g.buf.WriteString("},\n")
+		}
+		g.buf.WriteString("},\n")
+	}
+	g.buf.WriteString("}\n\n")
+
+	g.comment(fmt.Sprintf("URN returns the IANA registered SCIM name for the %s data structure and, together with ResourceType() identifies this code as implementing the Resource interface.", name))
+	fmt.Fprintf(&g.buf, "func (%s %s) URN() string {\nreturn %sURN\n}\n\n", recv, name, name)
+	g.comment(fmt.Sprintf("ResourceType returns the default structure describing the availability of the %s resource and, together with URN() identifies this code as implementing the Resource interface.", name))
+	fmt.Fprintf(&g.buf, "func (%s %s) ResourceType() scim.ResourceType {\nreturn %sResourceType\n}\n\n", recv, name, name)
+
+	g.buf.WriteString("//\n// JSON marshaling and unmarshaling\n//\n\n")
+	g.buf.WriteString("// MarshalJSON implements https://golang.org/pkg/encoding/json/#Marshaler\n")
+	fmt.Fprintf(&g.buf, "func (%s %s) MarshalJSON() ([]byte, error) {\n", recv, name)
+	fmt.Fprintf(&g.buf, "type %s %s\njson := ap.ConfigCompatibleWithStandardLibrary\nreturn json.Marshal((%s)(%s))\n}\n\n", alias, name, alias, recv)
+	g.buf.WriteString("// UnmarshalJSON implements https://golang.org/pkg/encoding/json/#Unmarshaler\n")
+	fmt.Fprintf(&g.buf, "func (%s *%s) UnmarshalJSON(data []byte) error {\n", recv, name)
+	fmt.Fprintf(&g.buf, "type %s %s\njson := ap.ConfigCompatibleWithStandardLibrary\nreturn json.Unmarshal(data, (*%s)(%s))\n}\n\n", alias, name, alias, recv)
+
+	g.nested(nested)
+}
+
+func (g *generator) extension(s scim.Schema, name string) {
+	g.comment(fmt.Sprintf("%s is the SCIM extension defined by the %s schema.", name, s.ID), s.Description)
+	nested := g.structure(name, s.Attributes, false, true)
+	g.urn(name, s)
+
+	g.comment(fmt.Sprintf("URN returns the %sURN but more importantly implements the Extension interface that identifies this struct as a SCIM extension.", name))
+	fmt.Fprintf(&g.buf, "func (%s %s) URN() string {\nreturn %sURN\n}\n\n", receiver(name), name, name)
+
+	g.nested(nested)
+}
+
+func (g *generator) urn(name string, s scim.Schema) {
+	g.comment(fmt.Sprintf("%sURN is the URN that identifies the %s schema.", name, name))
+	fmt.Fprintf(&g.buf, "const %sURN = %q\n\n", name, s.ID)
+}
+
+// urnExpr returns the expression for the URN - the generated constant if
+// there is one.
+func (g *generator) urnExpr(urn string) string {
+	if c, ok := g.consts[strings.ToLower(urn)]; ok {
+		return c
+	}
+	return fmt.Sprintf("%q", urn)
+}
+
+// complexType describes a struct that's generated for the sub-attributes
+// of a complex attribute.
+type complexType struct {
+	name  string
+	path  string
+	attrs []scim.Attribute
+}
+
+// structure writes the struct with a field for each of the attributes and
+// returns the structs that have to be generated for its complex
+// attributes.  Singular complex and dateTime attributes are pointers so
+// that omitempty leaves them out when they're unassigned.  Resources embed
+// scim.CommonAttributes and the fields of structs with methods are
+// renamed if they'd collide with them.
+func (g *generator) structure(name string, attrs []scim.Attribute, resource bool, methods bool) []complexType {
+	nested := []complexType{}
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+	if resource {
+		g.buf.WriteString("scim.CommonAttributes\n")
+	}
+	fields := map[string]bool{}
+	for _, attr := range attrs {
+		if resource && isCommonAttribute(attr.Name) {
+			continue
+		}
+		field := fieldName(attr.Name)
+		if methods && (field == "URN" || field == "ResourceType" || field == "MarshalJSON" || field == "UnmarshalJSON") {
+			field += "Value"
+		}
+		for n := 2; fields[field]; n++ {
+			field = fmt.Sprintf("%s%d", fieldName(attr.Name), n)
+		}
+		fields[field] = true
+
+		typ := g.goType(attr)
+		if attr.Type == scim.Complex {
+			typ = g.name(name + singular(field, attr.Multivalued))
+			nested = append(nested, complexType{name: typ, path: name + "." + attr.Name, attrs: attr.SubAttributes})
+		}
+		switch {
+		case attr.Multivalued:
+			typ = "[]" + typ
+		case attr.Type == scim.Complex || attr.Type == scim.DateTime:
+			typ = "*" + typ
+		}
+		tag := attr.Name
+		if !attr.Required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&g.buf, "%s %s `json:\"%s\"`", field, typ, tag)
+		if d := strings.Join(strings.Fields(attr.Description), " "); d != "" {
+			fmt.Fprintf(&g.buf, " //%s", d)
+		}
+		g.buf.WriteString("\n")
+	}
+	g.buf.WriteString("}\n\n")
+	return nested
+}
+
+func (g *generator) nested(types []complexType) {
+	for len(types) > 0 {
+		ct := types[0]
+		types = types[1:]
+		g.comment(fmt.Sprintf("%s is a value of the %s attribute.", ct.name, ct.path))
+		types = append(types, g.structure(ct.name, ct.attrs, false, false)...)
+	}
+}
+
+func (g *generator) goType(attr scim.Attribute) string {
+	switch attr.Type {
+	case scim.Boolean:
+		return "bool"
+	case scim.Decimal:
+		return "float64"
+	case scim.Integer:
+		return "int"
+	case scim.DateTime:
+		g.imports["time"] = true
+		return "time.Time"
+	default:
+		return "string"
+	}
+}
+
+// name returns a unique type name based on the provided name.
+func (g *generator) name(name string) string {
+	unique := name
+	for n := 2; g.names[unique]; n++ {
+		unique = fmt.Sprintf("%s%d", name, n)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// comment writes the paragraphs as a doc comment wrapped at commentWidth.
+func (g *generator) comment(paragraphs ...string) {
+	first := true
+	for _, p := range paragraphs {
+		words := strings.Fields(p)
+		if len(words) == 0 {
+			continue
+		}
+		if !first {
+			g.buf.WriteString("//\n")
+		}
+		first = false
+		line := "// " + words[0]
+		for _, w := range words[1:] {
+			if len(line)+1+len(w) > commentWidth {
+				g.buf.WriteString(line + "\n")
+				line = "// " + w
+				continue
+			}
+			line += " " + w
+		}
+		g.buf.WriteString(line + "\n")
+	}
+}
+
+// typeName returns the Go type name for the schema - based on its name
+// or, if it doesn't have one, on the last segment of its URN.
+func typeName(s scim.Schema) string {
+	if s.Name != "" {
+		return identifier(s.Name)
+	}
+	return identifier(s.ID[strings.LastIndex(s.ID, ":")+1:])
+}
+
+// fieldName returns the Go field name for the attribute.
+func fieldName(name string) string {
+	if name == "$ref" {
+		return "Reference"
+	}
+	return identifier(name)
+}
+
+// identifier returns an exported Go identifier built from the letters and
+// digits of s - each run of letters and digits is capitalized.
+func identifier(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	id := ""
+	for _, w := range words {
+		id += strings.ToUpper(w[:1]) + w[1:]
+	}
+	if id == "" || unicode.IsDigit(rune(id[0])) {
+		id = "X" + id
+	}
+	return id
+}
+
+// singular returns the singular form of a multi-valued attribute's field
+// name (e.g. Addresses becomes Address) so that it can name the type of
+// its values.
+func singular(field string, multivalued bool) string {
+	switch {
+	case !multivalued:
+		return field
+	case strings.HasSuffix(field, "ies"):
+		return strings.TrimSuffix(field, "ies") + "y"
+	case strings.HasSuffix(field, "sses"):
+		return strings.TrimSuffix(field, "es")
+	case strings.HasSuffix(field, "s") && !strings.HasSuffix(field, "ss"):
+		return strings.TrimSuffix(field, "s")
+	}
+	return field
+}
+
+func receiver(name string) string {
+	return strings.ToLower(name[:1])
+}
+
+// isCommonAttribute indicates whether the attribute is one of those
+// provided by scim.CommonAttributes.
+func isCommonAttribute(name string) bool {
+	for _, ca := range []string{"id", "externalId", "meta", "schemas"} {
+		if strings.EqualFold(name, ca) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PennState/proctor/pkg/goldenfile"
+	"github.com/PennState/scim-client/pkg/scim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	schemas := []scim.Schema{}
+	require.NoError(t, readList("testdata/schemas.json", &schemas))
+	resourceTypes := []scim.ResourceType{}
+	require.NoError(t, readList("testdata/resource_types.json", &resourceTypes))
+	enterpriseUser, _ := scim.CoreSchema(scim.EnterpriseUserURN)
+
+	tests := []struct {
+		name          string
+		goldenFile    string
+		schemas       []scim.Schema
+		resourceTypes []scim.ResourceType
+	}{
+		{"With resource types", "organization.go", schemas, resourceTypes},
+		{"Without resource types", "organization_heuristic.go", schemas, nil},
+		{"Core extension", "enterprise_user.go", []scim.Schema{enterpriseUser}, nil},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			src, err := generate("examples", test.schemas, test.resourceTypes)
+			require.NoError(t, err)
+			goldenfile.AssertStringEq(t, goldenfile.GetDefaultFilePath(test.goldenFile), string(src))
+		})
+	}
+}
+
+func TestDecodeList(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"ListResponse", `{"totalResults":1,"Resources":[{"id":"urn:com:example:2.0:Pantry"}]}`},
+		{"Array", ` [{"id":"urn:com:example:2.0:Pantry"}]`},
+		{"Single", `{"id":"urn:com:example:2.0:Pantry"}`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			schemas := []scim.Schema{}
+			require.NoError(t, decodeList([]byte(test.data), &schemas))
+			require.Len(t, schemas, 1)
+			assert.Equal(t, "urn:com:example:2.0:Pantry", schemas[0].ID)
+		})
+	}
+}
+
+func TestSelectSchemas(t *testing.T) {
+	schemas := scim.CoreSchemas()
+
+	selected, err := selectSchemas(schemas, scim.EnterpriseUserURN+", "+scim.UserURN)
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	assert.Equal(t, scim.EnterpriseUserURN, selected[0].ID)
+	assert.Equal(t, scim.UserURN, selected[1].ID)
+
+	_, err = selectSchemas(schemas, "urn:com:example:2.0:Pantry")
+	assert.EqualError(t, err, "schema not found: urn:com:example:2.0:Pantry")
+}
+
+const roundTripMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func main() {
+	b, err := json.Marshal(Organization{Name: "Tour Promotion"})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(b))
+
+	o := Organization{}
+	err = json.Unmarshal([]byte(` + "`" + `{"name":"Tour Promotion","established":"2011-05-13T04:42:34Z","parent":{"value":"26118915"}}` + "`" + `), &o)
+	if err != nil {
+		panic(err)
+	}
+	b, err = json.Marshal(o)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(b))
+}
+`
+
+// TestGeneratedRoundTrip compiles the generated code and checks that
+// unassigned attributes aren't encoded while assigned ones survive a
+// round-trip.
+func TestGeneratedRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiling the generated code is slow")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command isn't available")
+	}
+	schemas := []scim.Schema{}
+	require.NoError(t, readList("testdata/schemas.json", &schemas))
+	resourceTypes := []scim.ResourceType{}
+	require.NoError(t, readList("testdata/resource_types.json", &resourceTypes))
+
+	src, err := generate("main", schemas, resourceTypes)
+	require.NoError(t, err)
+	// The package has to be within the module to import its dependencies.
+	dir, err := ioutil.TempDir(".", "_roundtrip")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "organization.go"), src, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(roundTripMain), 0644))
+
+	cmd := exec.Command(gobin, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 2)
+
+	unassigned := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &unassigned))
+	assert.Equal(t, "Tour Promotion", unassigned["name"])
+	assert.NotContains(t, unassigned, "established")
+	assert.NotContains(t, unassigned, "parent")
+
+	assigned := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &assigned))
+	assert.Equal(t, "2011-05-13T04:42:34Z", assigned["established"])
+	assert.Equal(t, map[string]interface{}{"value": "26118915"}, assigned["parent"])
+}
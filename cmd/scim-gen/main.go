@@ -0,0 +1,191 @@
+package main
+
+//scim-gen generates Go types implementing the scim.Resource or
+//scim.Extension interfaces from SCIM schema definitions - see
+//https://tools.ietf.org/html/rfc7643#section-7.  The schemas can be read
+//from a file containing the JSON returned by a server's /Schemas endpoint
+//(a ListResponse), a JSON array of schemas or a single schema:
+//
+//	go run ./cmd/scim-gen -schemas schemas.json -package examples -o organization.go
+//
+//or retrieved from a live SCIM server:
+//
+//	go run ./cmd/scim-gen -url https://example.com/scim/v2 -urn urn:com:example:2.0:Organization
+//
+//Schemas that are the core schema of a resource type are generated as
+//resources, all others as extensions.  When the schemas are retrieved
+//from a server, its /ResourceTypes are used to tell them apart and to
+//generate the resources' ResourceType variables (the -resource-types flag
+//provides them when reading the schemas from a file).  Otherwise schemas
+//whose URN contains ":extension:" are considered to be extensions.
+//
+//If the following env variables are set, the server is accessed using
+//OAuth2 client credentials:
+//
+//- OAUTH_TOKEN_URL
+//- OAUTH_CLIENT_ID
+//- OAUTH_CLIENT_SECRET
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/PennState/scim-client/pkg/scim"
+	"github.com/kelseyhightower/envconfig"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+func main() {
+	schemaFile := flag.String("schemas", "", "file containing the schemas' JSON")
+	rtFile := flag.String("resource-types", "", "file containing the resource types' JSON (optional)")
+	url := flag.String("url", "", "service URL of the SCIM server that provides the schemas")
+	urns := flag.String("urn", "", "comma-separated URNs of the schemas to generate (default all)")
+	pkg := flag.String("package", "main", "package of the generated code")
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if (*schemaFile == "") == (*url == "") {
+		fmt.Fprintln(os.Stderr, "exactly one of -schemas or -url is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	schemas, resourceTypes, err := load(*schemaFile, *rtFile, *url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	schemas, err = selectSchemas(schemas, *urns)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(*pkg, schemas, resourceTypes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = ioutil.WriteFile(*out, src, 0644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// load returns the schemas and resource types from the files or, if the
+// url is provided, from the SCIM server.
+func load(schemaFile string, rtFile string, url string) ([]scim.Schema, []scim.ResourceType, error) {
+	schemas := []scim.Schema{}
+	resourceTypes := []scim.ResourceType{}
+	if url != "" {
+		c, err := scim.NewClient(httpClient(), url)
+		if err != nil {
+			return nil, nil, err
+		}
+		schemas, err = c.GetSchemas(context.Background())
+		if err != nil {
+			return nil, nil, err
+		}
+		resourceTypes, err = c.GetResourceTypes(context.Background())
+		if err != nil {
+			log.Warnf("Resource types aren't available - %v", err)
+		}
+		return schemas, resourceTypes, nil
+	}
+
+	err := readList(schemaFile, &schemas)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rtFile != "" {
+		err = readList(rtFile, &resourceTypes)
+	}
+	return schemas, resourceTypes, err
+}
+
+// readList unmarshals the file's ListResponse, JSON array or single JSON
+// object into the slice that v points to.
+func readList(name string, v interface{}) error {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return decodeList(data, v)
+}
+
+func decodeList(data []byte, v interface{}) error {
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "[") {
+		lr := struct {
+			Resources json.RawMessage `json:"Resources"`
+		}{}
+		err := json.Unmarshal(data, &lr)
+		if err != nil {
+			return err
+		}
+		trimmed = "[" + trimmed + "]"
+		if lr.Resources != nil {
+			trimmed = string(lr.Resources)
+		}
+	}
+	return json.Unmarshal([]byte(trimmed), v)
+}
+
+// selectSchemas returns the schemas with the comma-separated URNs (or all
+// of them if no URNs are provided).
+func selectSchemas(schemas []scim.Schema, urns string) ([]scim.Schema, error) {
+	if urns == "" {
+		return schemas, nil
+	}
+	selected := []scim.Schema{}
+	for _, urn := range strings.Split(urns, ",") {
+		found := false
+		for _, s := range schemas {
+			if strings.EqualFold(s.ID, strings.TrimSpace(urn)) {
+				selected = append(selected, s)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("schema not found: %s", urn)
+		}
+	}
+	return selected, nil
+}
+
+//
+//OAuth2 configuration
+//
+
+const oEnvPrefix = "oauth"
+
+type oAuthCfg struct {
+	TokenURL     string `split_words:"true"`
+	ClientID     string `split_words:"true"`
+	ClientSecret string `split_words:"true"`
+}
+
+// httpClient returns an OAuth2 client credentials client if the OAuth env
+// variables are set, otherwise the default client.
+func httpClient() *http.Client {
+	var oCfg oAuthCfg
+	err := envconfig.Process(oEnvPrefix, &oCfg)
+	if err != nil || oCfg.TokenURL == "" {
+		return http.DefaultClient
+	}
+	ccc := clientcredentials.Config{
+		TokenURL:     oCfg.TokenURL,
+		ClientID:     oCfg.ClientID,
+		ClientSecret: oCfg.ClientSecret,
+	}
+	return ccc.Client(oauth2.NoContext)
+}
@@ -8,6 +8,7 @@ require (
 	github.com/PennState/proctor v0.3.0
 	github.com/json-iterator/go v1.1.8 // indirect
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/onrik/logrus v0.4.1
 	github.com/sirupsen/logrus v1.4.2
 	github.com/stretchr/testify v1.5.1
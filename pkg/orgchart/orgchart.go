@@ -0,0 +1,284 @@
+/*
+Package orgchart builds reporting hierarchies from the manager attribute
+of the SCIM enterprise user extension
+(urn:ietf:params:scim:schemas:extension:enterprise:2.0:User).
+*/
+package orgchart
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PennState/scim-client/pkg/scim"
+)
+
+// CycleError is returned when a chain of managers loops back on itself.
+type CycleError struct {
+	IDs []string //IDs lists the user ids forming the cycle - the first id is repeated at the end.
+}
+
+func (ce CycleError) Error() string {
+	return fmt.Sprintf("manager cycle: %s", strings.Join(ce.IDs, " -> "))
+}
+
+// DanglingReference describes a user whose manager isn't known.
+type DanglingReference struct {
+	UserID    string
+	ManagerID string
+}
+
+// Node is a user and the nodes of the users that report directly to them.
+type Node struct {
+	User    *scim.User
+	Reports []*Node
+}
+
+// Chart is the reporting hierarchy of a set of users.
+type Chart struct {
+	users    map[string]*scim.User
+	managers map[string]string
+	reports  map[string][]string
+	dangling []DanglingReference
+	cycles   [][]string
+}
+
+// ManagerID returns the id of the provided user's manager from the user's
+// enterprise extension.  If the manager's value is missing, the id is
+// taken from the last segment of the manager's reference.  An empty string
+// is returned if the user has no manager.
+func ManagerID(user *scim.User) (string, error) {
+	if !user.HasExtensionByURN(scim.EnterpriseUserURN) {
+		return "", nil
+	}
+	eu := scim.EnterpriseUser{}
+	err := user.GetExtension(&eu)
+	if err != nil {
+		return "", err
+	}
+	if eu.Manager.Value != "" {
+		return eu.Manager.Value, nil
+	}
+	ref := strings.TrimRight(eu.Manager.Reference, "/")
+	return ref[strings.LastIndex(ref, "/")+1:], nil
+}
+
+// New builds the Chart of the provided users.  Users whose manager is not
+// in the set are reported by Dangling and every manager cycle is reported
+// by Cycles - in both cases the users are treated as the roots of their
+// own trees.
+func New(users ...*scim.User) (*Chart, error) {
+	c := &Chart{
+		users:    map[string]*scim.User{},
+		managers: map[string]string{},
+		reports:  map[string][]string{},
+	}
+	for _, u := range users {
+		c.users[u.ID] = u
+	}
+	for _, u := range users {
+		mid, err := ManagerID(u)
+		if err != nil {
+			return nil, err
+		}
+		if mid == "" {
+			continue
+		}
+		c.managers[u.ID] = mid
+		if _, ok := c.users[mid]; !ok {
+			c.dangling = append(c.dangling, DanglingReference{UserID: u.ID, ManagerID: mid})
+			continue
+		}
+		c.reports[mid] = append(c.reports[mid], u.ID)
+	}
+	for _, ids := range c.reports {
+		sort.Strings(ids)
+	}
+	sort.Slice(c.dangling, func(i, j int) bool {
+		return c.dangling[i].UserID < c.dangling[j].UserID
+	})
+	c.findCycles()
+	return c, nil
+}
+
+// findCycles records each distinct manager cycle.
+func (c *Chart) findCycles() {
+	ids := make([]string, 0, len(c.users))
+	for id := range c.users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	done := map[string]bool{}
+	for _, id := range ids {
+		path := []string{}
+		onPath := map[string]int{}
+		for cur := id; cur != "" && !done[cur]; cur = c.manager(cur) {
+			if idx, ok := onPath[cur]; ok {
+				c.cycles = append(c.cycles, append(append([]string{}, path[idx:]...), cur))
+				break
+			}
+			onPath[cur] = len(path)
+			path = append(path, cur)
+		}
+		for _, p := range path {
+			done[p] = true
+		}
+	}
+}
+
+// manager returns the id of the user's manager if the manager is in the
+// chart.
+func (c *Chart) manager(id string) string {
+	mid := c.managers[id]
+	if _, ok := c.users[mid]; !ok {
+		return ""
+	}
+	return mid
+}
+
+// Dangling returns the users whose manager is not in the chart.
+func (c *Chart) Dangling() []DanglingReference {
+	return c.dangling
+}
+
+// Cycles returns the manager cycles in the chart.
+func (c *Chart) Cycles() [][]string {
+	return c.cycles
+}
+
+// User returns the user with the provided id.
+func (c *Chart) User(id string) (*scim.User, bool) {
+	u, ok := c.users[id]
+	return u, ok
+}
+
+// Chain returns the ids of the provided user's managers starting with
+// the user's direct manager and ending with a user who has no manager
+// (or whose manager is not in the chart).  A CycleError is returned if
+// the chain loops.
+func (c *Chart) Chain(id string) ([]string, error) {
+	chain := []string{}
+	seen := map[string]int{id: -1}
+	for cur := c.manager(id); cur != ""; cur = c.manager(cur) {
+		if idx, ok := seen[cur]; ok {
+			start := append([]string{id}, chain...)
+			return chain, CycleError{IDs: append(start[idx+1:], cur)}
+		}
+		seen[cur] = len(chain)
+		chain = append(chain, cur)
+	}
+	return chain, nil
+}
+
+// DirectReports returns the ids of the users who report directly to the
+// user with the provided id.
+func (c *Chart) DirectReports(id string) []string {
+	return c.reports[id]
+}
+
+// AllReports returns the ids of the users who report directly or
+// indirectly to the user with the provided id.
+func (c *Chart) AllReports(id string) []string {
+	all := []string{}
+	seen := map[string]bool{id: true}
+	queue := append([]string{}, c.reports[id]...)
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if seen[cur] {
+			continue
+		}
+		seen[cur] = true
+		all = append(all, cur)
+		queue = append(queue, c.reports[cur]...)
+	}
+	return all
+}
+
+// SpanOfControl returns the number of users who report directly to the
+// user with the provided id.
+func (c *Chart) SpanOfControl(id string) int {
+	return len(c.reports[id])
+}
+
+// TotalSpanOfControl returns the number of users who report directly or
+// indirectly to the user with the provided id.
+func (c *Chart) TotalSpanOfControl(id string) int {
+	return len(c.AllReports(id))
+}
+
+// Roots returns the ids of the users at the top of the chart's trees -
+// users without a manager, users whose manager is not in the chart and,
+// for each cycle, the cycle's first user.
+func (c *Chart) Roots() []string {
+	roots := []string{}
+	for id := range c.users {
+		if c.manager(id) == "" {
+			roots = append(roots, id)
+		}
+	}
+	for _, cycle := range c.cycles {
+		roots = append(roots, cycle[0])
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// Tree returns the reporting tree below the user with the provided id.
+func (c *Chart) Tree(id string) *Node {
+	return c.tree(id, map[string]bool{})
+}
+
+func (c *Chart) tree(id string, seen map[string]bool) *Node {
+	seen[id] = true
+	n := &Node{User: c.users[id]}
+	for _, rid := range c.reports[id] {
+		if !seen[rid] {
+			n.Reports = append(n.Reports, c.tree(rid, seen))
+		}
+	}
+	return n
+}
+
+// Forest returns the reporting trees below each of the chart's Roots.
+func (c *Chart) Forest() []*Node {
+	seen := map[string]bool{}
+	forest := []*Node{}
+	for _, id := range c.Roots() {
+		forest = append(forest, c.tree(id, seen))
+	}
+	return forest
+}
+
+// Walk retrieves the provided user's chain of managers from the SCIM
+// server, starting with the user's direct manager.  A CycleError is
+// returned if the chain loops.
+func Walk(ctx context.Context, client *scim.Client, user *scim.User) ([]*scim.User, error) {
+	chain := []*scim.User{}
+	seen := map[string]bool{user.ID: true}
+	ids := []string{user.ID}
+	for cur := user; ; {
+		mid, err := ManagerID(cur)
+		if err != nil || mid == "" {
+			return chain, err
+		}
+		ids = append(ids, mid)
+		if seen[mid] {
+			for i, id := range ids {
+				if id == mid {
+					return chain, CycleError{IDs: ids[i:]}
+				}
+			}
+		}
+		seen[mid] = true
+		manager := &scim.User{}
+		err = client.RetrieveResource(ctx, manager, mid)
+		if err != nil {
+			return chain, err
+		}
+		chain = append(chain, manager)
+		cur = manager
+	}
+}
@@ -0,0 +1,120 @@
+package orgchart
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/PennState/scim-client/pkg/scim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func user(t *testing.T, id string, manager scim.Manager) *scim.User {
+	u := &scim.User{
+		CommonAttributes: scim.CommonAttributes{ID: id},
+		UserName:         id,
+	}
+	if manager != (scim.Manager{}) {
+		require.NoError(t, u.AddExtension(scim.EnterpriseUser{Manager: manager}))
+	}
+	return u
+}
+
+func users(t *testing.T) []*scim.User {
+	return []*scim.User{
+		user(t, "ceo", scim.Manager{}),
+		user(t, "cfo", scim.Manager{Value: "ceo"}),
+		user(t, "cto", scim.Manager{Reference: "../Users/ceo"}),
+		user(t, "dev1", scim.Manager{Value: "cto"}),
+		user(t, "dev2", scim.Manager{Value: "cto"}),
+		user(t, "acct", scim.Manager{Value: "cfo"}),
+		user(t, "temp", scim.Manager{Value: "agency"}),
+		user(t, "x", scim.Manager{Value: "y"}),
+		user(t, "y", scim.Manager{Value: "z"}),
+		user(t, "z", scim.Manager{Value: "x"}),
+	}
+}
+
+func TestChart(t *testing.T) {
+	c, err := New(users(t)...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []DanglingReference{{UserID: "temp", ManagerID: "agency"}}, c.Dangling())
+	assert.Equal(t, [][]string{{"x", "y", "z", "x"}}, c.Cycles())
+	assert.Equal(t, []string{"ceo", "temp", "x"}, c.Roots())
+
+	assert.Equal(t, []string{"cfo", "cto"}, c.DirectReports("ceo"))
+	assert.Equal(t, 2, c.SpanOfControl("ceo"))
+	assert.Equal(t, 5, c.TotalSpanOfControl("ceo"))
+	assert.ElementsMatch(t, []string{"cfo", "cto", "acct", "dev1", "dev2"}, c.AllReports("ceo"))
+	assert.Equal(t, 0, c.SpanOfControl("dev1"))
+
+	chain, err := c.Chain("dev2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cto", "ceo"}, chain)
+
+	_, err = c.Chain("y")
+	assert.Equal(t, CycleError{IDs: []string{"y", "z", "x", "y"}}, err)
+
+	tree := c.Tree("ceo")
+	assert.Equal(t, "ceo", tree.User.ID)
+	require.Len(t, tree.Reports, 2)
+	assert.Equal(t, "cto", tree.Reports[1].User.ID)
+	assert.Len(t, tree.Reports[1].Reports, 2)
+
+	forest := c.Forest()
+	assert.Len(t, forest, 3)
+	assert.Len(t, forest[2].Reports, 1)
+}
+
+func TestWalk(t *testing.T) {
+	byID := map[string]*scim.User{}
+	for _, u := range users(t) {
+		byID[u.ID] = u
+	}
+	hc := &http.Client{
+		Transport: roundTripper(func(req *http.Request) (*http.Response, error) {
+			id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+			u, ok := byID[id]
+			if !ok {
+				return response(404, `{"status":"404"}`), nil
+			}
+			body, err := json.Marshal(u)
+			require.NoError(t, err)
+			return response(200, string(body)), nil
+		}),
+	}
+	c, err := scim.NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+
+	chain, err := Walk(context.Background(), c, byID["dev1"])
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, "cto", chain[0].ID)
+	assert.Equal(t, "ceo", chain[1].ID)
+
+	_, err = Walk(context.Background(), c, byID["x"])
+	assert.Equal(t, CycleError{IDs: []string{"x", "y", "z", "x"}}, err)
+
+	_, err = Walk(context.Background(), c, byID["temp"])
+	assert.Error(t, err)
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func response(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Status:     http.StatusText(code),
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
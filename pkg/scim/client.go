@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/PennState/httputil/pkg/httperror"
 	"github.com/kelseyhightower/envconfig"
@@ -19,7 +21,11 @@ import (
 //SCIM client configuration
 //
 
-const envPrefix = "scim"
+const (
+	envPrefix               = "scim"
+	defaultMaxUpdateRetries = 3
+	defaultMaxConcurrency   = 8
+)
 
 //
 // Error messages
@@ -28,15 +34,22 @@ const envPrefix = "scim"
 const (
 	noServiceURLMessage      = "ServiceURL is a required configuration parameter"
 	invalidServiceURLMessage = "provided ServiceURL is not valid"
+	negativeRetriesMessage   = "MaxUpdateRetries must not be negative"
 )
 
 // clientConfig ..
 // ServiceURL is the base URI of the SCIM server's resources - see https://tools.ietf.org/html/rfc7644#section-1.3
 type clientCfg struct {
-	ServiceURL       string `split_words:"true" required:"true"`
-	IgnoreRedirects  bool   `split_words:"true" default:"false"`
-	DisableDiscovery bool   `split_words:"true" default:"false"`
-	DisableEtag      bool   `split_words:"true" default:"false"`
+	ServiceURL               string          `split_words:"true" required:"true"`
+	IgnoreRedirects          bool            `split_words:"true" default:"false"`
+	DisableDiscovery         bool            `split_words:"true" default:"false"`
+	DisableEtag              bool            `split_words:"true" default:"false"`
+	MaxUpdateRetries         int             `split_words:"true" default:"3"`
+	MaxConcurrency           int             `split_words:"true" default:"8"`
+	DefaultTenant            string          `split_words:"true"`
+	PatchDialect             Dialect         `split_words:"true" default:"standard"`
+	IncludeRequestAttributes bool            `split_words:"true" default:"false"`
+	Tenants                  *TenantRegistry `ignored:"true"`
 }
 
 //
@@ -63,13 +76,68 @@ func DisableEtag(disableEtag bool) ClientOpt {
 	}
 }
 
+// MaxUpdateRetries sets the number of times Update re-fetches and
+// re-applies its mutation after the server reports that the resource's
+// version has changed (HTTP status 412).  Zero disables retries and
+// negative values are rejected by NewClient.
+func MaxUpdateRetries(maxUpdateRetries int) ClientOpt {
+	return func(cfg *clientCfg) {
+		cfg.MaxUpdateRetries = maxUpdateRetries
+	}
+}
+
+// MaxConcurrency limits the number of requests the client makes in
+// parallel when a single method call requires many requests.
+func MaxConcurrency(maxConcurrency int) ClientOpt {
+	return func(cfg *clientCfg) {
+		cfg.MaxConcurrency = maxConcurrency
+	}
+}
+
+// Tenants configures the client to route each request to the Tenant named
+// by the request's context (see WithTenant).
+func Tenants(tenants *TenantRegistry) ClientOpt {
+	return func(cfg *clientCfg) {
+		cfg.Tenants = tenants
+	}
+}
+
+// DefaultTenant names the Tenant used when a request's context does not
+// include one.
+func DefaultTenant(name string) ClientOpt {
+	return func(cfg *clientCfg) {
+		cfg.DefaultTenant = name
+	}
+}
+
+// PatchDialect sets the variant of the PatchOp request body that the SCIM
+// server expects (see Dialect).
+func PatchDialect(dialect Dialect) ClientOpt {
+	return func(cfg *clientCfg) {
+		cfg.PatchDialect = dialect
+	}
+}
+
+// IncludeRequestAttributes causes RetrieveResource and QueryResourceType
+// to request the attributes that are only returned when they're
+// explicitly requested (see RequestAttributes).  Queries use the Go type
+// registered for the ResourceType (see ResourceRegistry.RegisterResource)
+// and queries of the server root are sent unchanged.
+func IncludeRequestAttributes(includeRequestAttributes bool) ClientOpt {
+	return func(cfg *clientCfg) {
+		cfg.IncludeRequestAttributes = includeRequestAttributes
+	}
+}
+
 //
 //SCIM client
 //
 
 type client struct {
-	cfg  *clientCfg
-	http *http.Client
+	cfg        *clientCfg
+	http       *http.Client
+	spcs       sync.Map // ServiceURL -> ServiceProviderConfig
+	discovered sync.Map // ServiceURL -> []Schema
 }
 
 //Client allows request scim resources
@@ -84,18 +152,23 @@ type Client struct {
 func NewClient(http *http.Client, url string, opts ...ClientOpt) (*Client, error) {
 	cfg := clientCfg{}
 	cfg.ServiceURL = url
+	cfg.MaxUpdateRetries = defaultMaxUpdateRetries
+	cfg.MaxConcurrency = defaultMaxConcurrency
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 	return newClient(http, &cfg)
 }
 
-func NewClientFromEnv(http *http.Client) (*Client, error) {
+func NewClientFromEnv(http *http.Client, opts ...ClientOpt) (*Client, error) {
 	cfg := clientCfg{}
 	err := envconfig.Process(envPrefix, &cfg)
 	if err != nil {
 		return nil, err
 	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	return newClient(http, &cfg)
 }
 
@@ -109,6 +182,12 @@ func newClient(http *http.Client, cfg *clientCfg) (*Client, error) {
 	if err != nil {
 		return nil, errors.New(invalidServiceURLMessage)
 	}
+	if !cfg.PatchDialect.valid() {
+		return nil, invalidDialect(cfg.PatchDialect)
+	}
+	if cfg.MaxUpdateRetries < 0 {
+		return nil, errors.New(negativeRetriesMessage)
+	}
 
 	// String trailing slash from SCIM server URL (all resource paths include a
 	// leading slash)
@@ -131,7 +210,16 @@ func newClient(http *http.Client, cfg *clientCfg) (*Client, error) {
 // RetrieveResource populates the provided (and presumably empty) resourcs
 // with data associated with the provided id from the SCIM servers storage.
 func (c Client) RetrieveResource(ctx context.Context, res Resource, id string) error {
-	path := c.cfg.ServiceURL + res.ResourceType().Endpoint + "/" + id
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return err
+	}
+	path := url + res.ResourceType().Endpoint + "/" + id
+	query, err := c.requestAttributesQuery(ctx, res)
+	if err != nil {
+		return err
+	}
+	path += query
 
 	log.Debugf("Path: %s", path)
 	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
@@ -143,20 +231,25 @@ func (c Client) RetrieveResource(ctx context.Context, res Resource, id string) e
 }
 
 //SearchResource ..
-func (c Client) QueryResourceType(ctx context.Context, rt ResourceType, sr SearchRequest) (ListResponse, error) {
-	path := c.cfg.ServiceURL + rt.Endpoint + "/.search"
-	return c.query(ctx, path, sr)
+func (c Client) QueryResourceType(ctx context.Context, rt ResourceType, sr SearchRequest, opts ...QueryOpt) (ListResponse, error) {
+	return c.partitionedQuery(ctx, &rt, rt.Endpoint+"/.search", sr, opts...)
 }
 
 //SearchServer ..
-func (c Client) QueryServer(ctx context.Context, sr SearchRequest) (ListResponse, error) {
-	path := c.cfg.ServiceURL + "/.search"
-	return c.query(ctx, path, sr)
+func (c Client) QueryServer(ctx context.Context, sr SearchRequest, opts ...QueryOpt) (ListResponse, error) {
+	return c.partitionedQuery(ctx, nil, "/.search", sr, opts...)
 }
 
-func (c Client) query(ctx context.Context, path string, sr SearchRequest) (ListResponse, error) {
+// query performs a single query.  If resourceType is provided, the
+// resources are unmarshaled as its registered Go type (see ListResponse).
+func (c Client) query(ctx context.Context, endpoint string, sr SearchRequest, resourceType string) (ListResponse, error) {
+	lr := ListResponse{resourceType: resourceType}
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return lr, err
+	}
+	path := url + endpoint
 	log.Debug("Path: ", path)
-	lr := ListResponse{}
 
 	// TODO: Remove this after SCIMple is fixed
 	if sr.SortOrder == NotSpecified {
@@ -180,16 +273,21 @@ func (c Client) query(ctx context.Context, path string, sr SearchRequest) (ListR
 
 // CreateResource adds the provided resource to those stored by the SCIM
 // server, returning an updated version that includes the generated id
-// value as well as Meta data.
+// value as well as Meta data.  ReadOnly and empty writeOnly attributes
+// aren't sent (see MarshalForWrite).
 func (c Client) CreateResource(ctx context.Context, res Resource) error {
 	log.Trace("(c Client) ReplaceResource(res)")
-	rj, err := json.Marshal(res)
+	rj, err := c.marshalForWrite(ctx, res)
 	if err != nil {
 		return err
 	}
 	log.Debug("Marshaled resource: ", string(rj))
 
-	path := c.cfg.ServiceURL + res.ResourceType().Endpoint
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return err
+	}
+	path := url + res.ResourceType().Endpoint
 	req, err := http.NewRequestWithContext(ctx, "POST", path, bytes.NewReader(rj))
 	if err != nil {
 		return err
@@ -199,16 +297,21 @@ func (c Client) CreateResource(ctx context.Context, res Resource) error {
 }
 
 // ReplaceResource updates the data on the SCIM server that's associated
-// with the provided id.
+// with the provided id.  ReadOnly and empty writeOnly attributes aren't
+// sent (see MarshalForWrite).
 func (c Client) ReplaceResource(ctx context.Context, res Resource) error {
 	log.Trace("(c Client) ReplaceResource(res)")
-	rj, err := json.Marshal(res)
+	rj, err := c.marshalForWrite(ctx, res)
 	if err != nil {
 		return err
 	}
 	log.Debug("Marshaled resource: ", string(rj))
 
-	path := c.cfg.ServiceURL + res.ResourceType().Endpoint + "/" + res.getID()
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return err
+	}
+	path := url + res.ResourceType().Endpoint + "/" + res.getID()
 	req, err := http.NewRequestWithContext(ctx, "PUT", path, bytes.NewReader(rj))
 	if err != nil {
 		return err
@@ -218,11 +321,40 @@ func (c Client) ReplaceResource(ctx context.Context, res Resource) error {
 	return c.resourceOrError(res, req)
 }
 
+// ModifyResource applies the operations in the provided PatchOp to the
+// resource on the SCIM server that's associated with the resource's id.
+// If the server returns the modified resource, the provided resource is
+// updated with the server's representation.  The PatchOp is sent in the
+// client's PatchDialect.
+func (c Client) ModifyResource(ctx context.Context, res Resource, po PatchOp) error {
+	log.Trace("(c Client) ModifyResource(res, po)")
+	po, err := c.cfg.PatchDialect.Encode(po)
+	if err != nil {
+		return err
+	}
+	pj, err := json.Marshal(po)
+	if err != nil {
+		return err
+	}
+	log.Debug("Marshaled PatchOp: ", string(pj))
+
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return err
+	}
+	path := url + res.ResourceType().Endpoint + "/" + res.getID()
+	req, err := http.NewRequestWithContext(ctx, "PATCH", path, bytes.NewReader(pj))
+	if err != nil {
+		return err
+	}
+	c.etag(res, req)
+	return c.resourceOrError(res, req)
+}
+
 //
 // Not-yet-implemented
 //
 
-//func ModifyResource(res *Resource) - PATCH is not available via SCIMple
 //func DeleteResource(res *Resource) error
 //func Bulk
 
@@ -232,14 +364,40 @@ func (c Client) ReplaceResource(ctx context.Context, res Resource) error {
 
 func (c Client) GetResourceTypes(ctx context.Context) ([]ResourceType, error) {
 	resourceTypes := []ResourceType{}
-	err := c.getServerDiscoveryResources(ctx, ResourceTypeResourceType, resourceTypes)
+	err := c.getServerDiscoveryResources(ctx, ResourceTypeResourceType, &resourceTypes)
 	return resourceTypes, err
 }
 
+// GetSchemas retrieves the schemas published by the SCIM server.  The
+// schemas are retained by the client and used (instead of the core
+// schemas) when marshaling resources for create and replace requests (see
+// MarshalForWrite).
 func (c Client) GetSchemas(ctx context.Context) ([]Schema, error) {
 	schemas := []Schema{}
 	err := c.getServerDiscoveryResources(ctx, SchemaResourceType, &schemas)
-	return schemas, err
+	if err != nil {
+		return schemas, err
+	}
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return schemas, err
+	}
+	c.discovered.Store(url, schemas)
+	return schemas, nil
+}
+
+// serverSchemas returns the schemas of the SCIM server associated with the
+// provided context, retrieving them with GetSchemas unless they already
+// have been.
+func (c Client) serverSchemas(ctx context.Context) ([]Schema, error) {
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := c.discovered.Load(url); ok {
+		return v.([]Schema), nil
+	}
+	return c.GetSchemas(ctx)
 }
 
 func (c Client) GetServiceProviderConfig(ctx context.Context) (ServiceProviderConfig, error) {
@@ -248,13 +406,71 @@ func (c Client) GetServiceProviderConfig(ctx context.Context) (ServiceProviderCo
 	return cfg, err
 }
 
+// serviceProviderConfig returns the (cached) ServiceProviderConfig of the
+// SCIM server associated with the provided context or nil if server
+// discovery is disabled.
+func (c Client) serviceProviderConfig(ctx context.Context) (*ServiceProviderConfig, error) {
+	if c.cfg.DisableDiscovery {
+		return nil, nil
+	}
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := c.spcs.Load(url); ok {
+		spc := v.(ServiceProviderConfig)
+		return &spc, nil
+	}
+	spc, err := c.GetServiceProviderConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.spcs.Store(url, spc)
+	return &spc, nil
+}
+
+// getServerDiscoveryResources retrieves all the resources from one of the
+// server discovery endpoints into the provided slice pointer.  Servers
+// return either a ListResponse or, less commonly, a bare JSON array.
 func (c Client) getServerDiscoveryResources(ctx context.Context, typ ResourceType, res interface{}) error {
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url+typ.Endpoint, nil)
+	if err != nil {
+		return err
+	}
+	var body json.RawMessage
+	err = c.resourceOrError(&body, req)
+	if err != nil {
+		return err
+	}
+
+	list := struct {
+		Resources json.RawMessage `json:"Resources"`
+	}{}
+	if json.Unmarshal(body, &list) == nil && list.Resources != nil {
+		body = list.Resources
+	}
+	err = json.Unmarshal(body, res)
+	if err != nil {
+		return CodecError{
+			Err:  err.Error(),
+			Op:   Unmarshal,
+			Body: body,
+		}
+	}
 	return nil
 }
 
 func (c Client) getServerDiscoveryResource(ctx context.Context, res Resource) error {
 	log.Debugf("Type: %v", reflect.TypeOf(res))
-	path := c.cfg.ServiceURL + res.ResourceType().Endpoint
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return err
+	}
+	path := url + res.ResourceType().Endpoint
 	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
 	if err != nil {
 		return err
@@ -294,9 +510,29 @@ func (c Client) error(resp *http.Response) error {
 	return er
 }
 
+// etag adds an If-Match precondition containing the resource's version
+// (if it has one).  Setting the resource's meta.version to "*" requests
+// that the server only replace a resource that currently exists.  A
+// version that isn't a valid entity-tag is sent as a strong entity-tag
+// (or, if it contains quotes, not at all) since servers expect their
+// version back verbatim.
 func (c Client) etag(res Resource, req *http.Request) {
-	if !c.cfg.DisableEtag {
-		req.Header.Set("If-Match", res.getMeta().Version)
+	if c.cfg.DisableEtag {
+		return
+	}
+	version := res.getMeta().Version
+	etag, err := ParseETag(version)
+	if err != nil {
+		tag := strings.TrimSpace(version)
+		if strings.Contains(tag, "\"") {
+			log.Warnf("Omitting If-Match: %v", err)
+			return
+		}
+		log.Warnf("Sending meta.version %q as a strong entity-tag: %v", version, err)
+		etag = ETag{Tag: tag}
+	}
+	if !etag.IsZero() {
+		req.Header.Set("If-Match", etag.String())
 	}
 }
 
@@ -305,15 +541,40 @@ func (c Client) mime(req *http.Request) {
 	req.Header.Set("Content-Type", "application/scim+json")
 }
 
+// do sends the request using the http.Client and headers of the tenant
+// (if any) associated with the request's context.
+func (c Client) do(req *http.Request) (*http.Response, error) {
+	t, err := c.tenant(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return c.http.Do(req)
+	}
+	for k, vs := range t.Header {
+		req.Header.Del(k)
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if t.HTTP != nil {
+		return t.HTTP.Do(req)
+	}
+	return c.http.Do(req)
+}
+
 func (c Client) resourceOrError(res interface{}, req *http.Request) error {
 	c.mime(req)
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		return c.error(resp)
 	}
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
 	return c.resource(resp, res)
 }
 
@@ -332,5 +593,18 @@ func (c Client) resource(resp *http.Response, res interface{}) error {
 		}
 	}
 
+	// Prefer the ETag header over meta.version since the header is the
+	// value the server will compare If-Match preconditions against.
+	if r, ok := res.(Resource); ok {
+		if header := resp.Header.Get("ETag"); header != "" {
+			etag, err := ParseETag(header)
+			if err == nil {
+				r.getMeta().Version = etag.String()
+			} else {
+				log.Warn("Ignoring unparseable ETag header: ", err)
+			}
+		}
+	}
+
 	return nil
 }
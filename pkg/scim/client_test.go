@@ -1,6 +1,7 @@
 package scim
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -159,26 +160,33 @@ func TestError(t *testing.T) {
 }
 
 func TestETag(t *testing.T) {
-	vers := "W\\/\"3694e05e9dff590\""
-	res := User{
-		CommonAttributes: CommonAttributes{
-			Meta: Meta{
-				Version: vers,
-			},
-		},
-	}
-
 	tests := []struct {
 		name     string
 		disabled bool
+		version  string
+		exp      string
 	}{
-		{"ETags disabled", true},
-		{"ETags enabled", false},
+		{"ETags disabled", true, "W/\"3694e05e9dff590\"", ""},
+		{"ETags enabled", false, "W/\"3694e05e9dff590\"", "W/\"3694e05e9dff590\""},
+		{"JSON escaped weak ETag", false, "W\\/\"3694e05e9dff590\"", "W/\"3694e05e9dff590\""},
+		{"Unquoted ETag", false, "3694e05e9dff590", "\"3694e05e9dff590\""},
+		{"Invalid ETag", false, "2019-10-18 21:04:47", "\"2019-10-18 21:04:47\""},
+		{"Invalid ETag with quotes", false, "\"3694e05e9dff590", ""},
+		{"Wildcard", false, "*", "*"},
+		{"No version", false, "", ""},
 	}
 
 	for idx := range tests {
 		test := tests[idx]
 		t.Run(test.name, func(t *testing.T) {
+			res := User{
+				CommonAttributes: CommonAttributes{
+					Meta: Meta{
+						Version: test.version,
+					},
+				},
+			}
+
 			c := Client{
 				client: &client{
 					cfg: &clientCfg{
@@ -193,19 +201,28 @@ func TestETag(t *testing.T) {
 
 			c.etag(&res, &req)
 
-			if test.disabled {
+			if test.exp == "" {
 				assert.NotContains(t, req.Header, "If-Match")
 				return
 			}
 
 			assert.Contains(t, req.Header, "If-Match")
-			exp := []string{}
-			exp = append(exp, vers)
-			assert.Equal(t, exp, req.Header["If-Match"])
+			assert.Equal(t, []string{test.exp}, req.Header["If-Match"])
 		})
 	}
 }
 
+func TestETagHeaderOverridesVersion(t *testing.T) {
+	resp := jsonResponse(200, `{"id":"2819c223","userName":"bjensen","meta":{"version":"W/\"1\""}}`)
+	resp.Header.Set("ETag", "\"2\"")
+	c := Client{
+		client: &client{},
+	}
+	user := User{}
+	assert.NoError(t, c.resource(resp, &user))
+	assert.Equal(t, "\"2\"", user.Meta.Version)
+}
+
 func TestResourceOrError(t *testing.T) {
 	const minuser = `
 	{
@@ -331,3 +348,95 @@ func TestResourceOrError(t *testing.T) {
 		})
 	}
 }
+
+func TestGetServerDiscoveryResources(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"ListResponse", `{"schemas":["urn:ietf:params:scim:api:messages:2.0:ListResponse"],"totalResults":1,"Resources":[{"id":"urn:ietf:params:scim:schemas:core:2.0:User","name":"User","attributes":[{"name":"userName","type":"string"}]}]}`},
+		{"Array", `[{"id":"urn:ietf:params:scim:schemas:core:2.0:User","name":"User","attributes":[{"name":"userName","type":"string"}]}]`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			var path string
+			hc := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					path = req.URL.Path
+					return jsonResponse(200, test.body), nil
+				}),
+			}
+			c, err := NewClient(hc, "https://example.com/v2")
+			assert.NoError(t, err)
+
+			schemas, err := c.GetSchemas(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "/v2/Schemas", path)
+			assert.Len(t, schemas, 1)
+			assert.Equal(t, UserURN, schemas[0].ID)
+			assert.Equal(t, []Attribute{{Name: "userName", Type: String}}, schemas[0].Attributes)
+		})
+	}
+}
+
+func TestGetResourceTypes(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(200, `{"totalResults":1,"Resources":[{"id":"User","name":"User","endpoint":"/Users","schema":"urn:ietf:params:scim:schemas:core:2.0:User"}]}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/v2")
+	assert.NoError(t, err)
+
+	resourceTypes, err := c.GetResourceTypes(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, resourceTypes, 1)
+	assert.Equal(t, "/Users", resourceTypes[0].Endpoint)
+	assert.Equal(t, UserURN, resourceTypes[0].Schema)
+}
+
+func TestModifyResourceDialect(t *testing.T) {
+	var body string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			b, _ := ioutil.ReadAll(req.Body)
+			body = string(b)
+			return &http.Response{StatusCode: http.StatusNoContent, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/v2", PatchDialect(EntraIDDialect), DisableEtag(true))
+	assert.NoError(t, err)
+
+	u := User{CommonAttributes: CommonAttributes{ID: "2819c223"}}
+	err = c.ModifyResource(context.Background(), &u, PatchOp{Operations: []PatchOperation{
+		{Op: PatchReplace, Path: "active", Value: true},
+	}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","value":{"active":"True"}}]}`, body)
+}
+
+func TestUnknownPatchDialect(t *testing.T) {
+	_, err := NewClient(nil, "https://example.com/v2", PatchDialect("okta"))
+	assert.EqualError(t, err, unknownDialectMessage+`: "okta"`)
+}
+
+// roundTripFunc adapts a function to the http.RoundTripper interface so
+// that tests can inspect requests and script responses.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// jsonResponse returns an HTTP response with the provided status code and
+// body.
+func jsonResponse(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Status:     http.StatusText(code),
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
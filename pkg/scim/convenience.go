@@ -2,30 +2,184 @@ package scim
 
 import (
 	"context"
-)
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 
-const (
-	resourcesByExternalID = "externalId EQ \"%s\""
-	userByUserName        = "userName EQ \"%s\""
+	"github.com/PennState/scim-client/pkg/scim/filter"
 )
 
+// byExternalID returns a search request for resources with the provided
+// externalId.
+func byExternalID(externalID string) SearchRequest {
+	return SearchRequest{Filter: filter.Attr("externalId").Eq(externalID).String()}
+}
+
+// byUserName returns a search request for the User with the provided
+// userName.
+func byUserName(userName string) SearchRequest {
+	return SearchRequest{Filter: filter.Attr("userName").Eq(userName).String()}
+}
+
 // QueryResourcesByExternalID is a helper method for retrieving resources
 // from a ResourceType by ExternalID
 func (c Client) QueryResourceTypeByExternalID(
 	ctx context.Context,
 	rt ResourceType,
-	externalID string) (ListResponse, error) {
-	return c.QueryResourceType(ctx, rt, NewSearchRequestFromFormat(resourcesByExternalID, externalID))
+	externalID string,
+	opts ...QueryOpt) (ListResponse, error) {
+	return c.QueryResourceType(ctx, rt, byExternalID(externalID), opts...)
 }
 
 // QueryServerByExternalID is a helper method for retrieving any resources
 // from the server by ExternalID
-func (c Client) QueryServerByExternalID(ctx context.Context, externalID string) (ListResponse, error) {
-	return c.QueryServer(ctx, NewSearchRequestFromFormat(resourcesByExternalID, externalID))
+func (c Client) QueryServerByExternalID(ctx context.Context, externalID string, opts ...QueryOpt) (ListResponse, error) {
+	return c.QueryServer(ctx, byExternalID(externalID), opts...)
 }
 
 // QueryUserResourcesByUserName is a helper method for retrieving User
 // resources by UserName
-func (c Client) QueryUserResourceTypeByUserName(ctx context.Context, userName string) (ListResponse, error) {
-	return c.QueryResourceType(ctx, UserResourceType, NewSearchRequestFromFormat(userByUserName, userName))
+func (c Client) QueryUserResourceTypeByUserName(ctx context.Context, userName string, opts ...QueryOpt) (ListResponse, error) {
+	return c.QueryResourceType(ctx, UserResourceType, byUserName(userName), opts...)
+}
+
+//
+// Upsert
+//
+
+const noExternalIDMessage = "resource must have an externalId to be upserted"
+
+// UpsertAction describes the operation performed by Upsert.
+type UpsertAction string
+
+const (
+	Created  UpsertAction = "Created"
+	Replaced UpsertAction = "Replaced"
+	Modified UpsertAction = "Modified"
+)
+
+// UpsertOpt changes the behavior of Upsert.
+type UpsertOpt func(*upsertCfg)
+
+type upsertCfg struct {
+	patch bool
+}
+
+// UpsertWithPatch causes Upsert to update a matching resource using the
+// PATCH operations that transform it into the provided resource (see
+// Diff) rather than replacing it - for servers that reject (or only
+// partially support) PUT.
+func UpsertWithPatch() UpsertOpt {
+	return func(cfg *upsertCfg) {
+		cfg.patch = true
+	}
+}
+
+// MultipleMatchesError is returned by Upsert when more than one resource
+// on the SCIM server shares the externalId of the resource being
+// upserted.
+type MultipleMatchesError struct {
+	ExternalID string
+	IDs        []string
+}
+
+func (mme MultipleMatchesError) Error() string {
+	return fmt.Sprintf("externalId %s matches %d resources: %v", mme.ExternalID, len(mme.IDs), mme.IDs)
+}
+
+// Upsert creates the provided resource on the SCIM server if no resource
+// of the same ResourceType has the resource's externalId, otherwise it
+// replaces (or, with UpsertWithPatch, modifies) the matching resource.  In
+// both cases the provided resource is updated with the server's
+// representation and the action that was taken is returned.  No action is
+// returned with an error.
+func (c Client) Upsert(ctx context.Context, res Resource, opts ...UpsertOpt) (UpsertAction, error) {
+	cfg := upsertCfg{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	externalID := res.getExternalID()
+	if externalID == "" {
+		return "", errors.New(noExternalIDMessage)
+	}
+
+	lr, err := c.QueryResourceTypeByExternalID(ctx, res.ResourceType(), externalID)
+	if err != nil {
+		return "", err
+	}
+
+	var action UpsertAction
+	switch len(lr.Resources) {
+	case 0:
+		action, err = Created, c.CreateResource(ctx, res)
+	case 1:
+		match := lr.Resources[0]
+		res.setID(match.getID())
+		res.getMeta().Version = match.getMeta().Version
+		if cfg.patch {
+			action, err = Modified, c.modify(ctx, match, res)
+			break
+		}
+		action, err = Replaced, c.ReplaceResource(ctx, res)
+	default:
+		mme := MultipleMatchesError{
+			ExternalID: externalID,
+		}
+		for _, match := range lr.Resources {
+			mme.IDs = append(mme.IDs, match.getID())
+		}
+		return "", mme
+	}
+	if err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+// modify sends the PATCH operations that transform the server's copy of
+// the resource into the provided resource.  The server's copy is decoded
+// as the provided resource's type (the ListResponse decodes resources as
+// the registered type of their resource type) so that they can be
+// compared.
+func (c Client) modify(ctx context.Context, match Resource, res Resource) error {
+	current, err := copyResource(match, res)
+	if err != nil {
+		return err
+	}
+	modified, err := c.patch(ctx, current, res)
+	if err != nil || modified {
+		return err
+	}
+	reflect.ValueOf(res).Elem().Set(reflect.ValueOf(current).Elem())
+	return nil
+}
+
+// patch sends the PATCH operations that transform the from resource into
+// the to resource (see Diff) and indicates whether there were any.
+func (c Client) patch(ctx context.Context, from Resource, to Resource) (bool, error) {
+	schemas, err := c.schemas(ctx)
+	if err != nil {
+		return false, err
+	}
+	po, err := Diff(from, to, schemas...)
+	if err != nil || len(po.Operations) == 0 {
+		return false, err
+	}
+	return true, c.ModifyResource(ctx, to, po)
+}
+
+// copyResource returns a copy of the resource decoded as the Go type of
+// the typ resource.
+func copyResource(res Resource, typ Resource) (Resource, error) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	cp := reflect.New(reflect.TypeOf(typ).Elem()).Interface().(Resource)
+	err = json.Unmarshal(data, cp)
+	if err != nil {
+		return nil, err
+	}
+	return cp, nil
 }
@@ -0,0 +1,113 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsert(t *testing.T) {
+	const (
+		match    = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"%s","externalId":"701984","userName":"bjensen","meta":{"version":"W/\"1\""}}`
+		response = `{"schemas":["urn:ietf:params:scim:api:messages:2.0:ListResponse"],"totalResults":%d,"Resources":[%s]}`
+	)
+
+	tests := []struct {
+		name    string
+		matches []string
+		opts    []UpsertOpt
+		action  UpsertAction
+		method  string
+		path    string
+		ifMatch string
+		err     error
+	}{
+		{"No match", nil, nil, Created, "POST", "/scim/Users", "", nil},
+		{"Single match", []string{"2819c223"}, nil, Replaced, "PUT", "/scim/Users/2819c223", "W/\"1\"", nil},
+		{"Single match with PATCH", []string{"2819c223"}, []UpsertOpt{UpsertWithPatch()}, Modified, "PATCH", "/scim/Users/2819c223", "W/\"1\"", nil},
+		{"Multiple matches", []string{"2819c223", "902c246b"}, nil, "", "", "", "", MultipleMatchesError{ExternalID: "701984", IDs: []string{"2819c223", "902c246b"}}},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			var write *http.Request
+			var body []byte
+			hc := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path == "/scim/Users/.search" {
+						resources := ""
+						for i, id := range test.matches {
+							if i > 0 {
+								resources += ","
+							}
+							resources += fmt.Sprintf(match, id)
+						}
+						return jsonResponse(200, fmt.Sprintf(response, len(test.matches), resources)), nil
+					}
+					write = req
+					body, _ = ioutil.ReadAll(req.Body)
+					if req.Method == "PATCH" {
+						return jsonResponse(http.StatusNoContent, ""), nil
+					}
+					return jsonResponse(200, string(body)), nil
+				}),
+			}
+			c, err := NewClient(hc, "https://example.com/scim")
+			require.NoError(t, err)
+
+			user := User{
+				CommonAttributes: CommonAttributes{
+					ExternalID: "701984",
+				},
+				UserName:    "bjensen",
+				DisplayName: "Babs Jensen",
+			}
+			action, err := c.Upsert(context.Background(), &user, test.opts...)
+			assert.Equal(t, test.action, action)
+			if test.err != nil {
+				assert.Equal(t, test.err, err)
+				assert.Nil(t, write)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, write)
+			assert.Equal(t, test.method, write.Method)
+			assert.Equal(t, test.path, write.URL.Path)
+			assert.Equal(t, test.ifMatch, write.Header.Get("If-Match"))
+			if test.method == "PATCH" {
+				assert.JSONEq(t, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"add","path":"displayName","value":"Babs Jensen"}]}`, string(body))
+			}
+		})
+	}
+}
+
+func TestUpsertRequiresExternalID(t *testing.T) {
+	c, err := NewClient(nil, "https://example.com/scim")
+	require.NoError(t, err)
+	_, err = c.Upsert(context.Background(), &User{})
+	assert.EqualError(t, err, noExternalIDMessage)
+}
+
+func TestUpsertFailure(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/scim/Users/.search" {
+				return jsonResponse(200, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:ListResponse"],"totalResults":0,"Resources":[]}`), nil
+			}
+			return jsonResponse(409, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"scimType":"uniqueness","status":"409"}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+
+	user := User{CommonAttributes: CommonAttributes{ExternalID: "701984"}, UserName: "bjensen"}
+	action, err := c.Upsert(context.Background(), &user)
+	assert.Error(t, err)
+	assert.Equal(t, UpsertAction(""), action)
+}
@@ -0,0 +1,78 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoreSchemas(t *testing.T) {
+	schemas := CoreSchemas()
+	require.Len(t, schemas, 6)
+	ids := make([]string, len(schemas))
+	for i, s := range schemas {
+		ids[i] = s.ID
+		assert.NotEmpty(t, s.Name)
+		assert.NotEmpty(t, s.Attributes)
+		assert.Equal(t, []string{SchemaURN}, s.Schemas)
+	}
+	assert.Equal(t, []string{UserURN, GroupURN, EnterpriseUserURN, ServiceProviderConfigURN, ResourceTypeURN, SchemaURN}, ids)
+}
+
+func TestCoreSchema(t *testing.T) {
+	s, ok := CoreSchema("URN:IETF:PARAMS:SCIM:SCHEMAS:CORE:2.0:USER")
+	require.True(t, ok)
+	assert.Equal(t, UserURN, s.ID)
+
+	userName := findAttribute(s.Attributes, "userName")
+	require.NotNil(t, userName)
+	assert.Equal(t, Attribute{
+		Name:        "userName",
+		Type:        String,
+		Description: userName.Description,
+		Required:    true,
+		Mutability:  ReadWrite,
+		Returned:    Default,
+		Uniqueness:  Server,
+	}, *userName)
+
+	emails := findAttribute(s.Attributes, "emails")
+	require.NotNil(t, emails)
+	assert.True(t, emails.Multivalued)
+	assert.Equal(t, []string{"work", "home", "other"}, findAttribute(emails.SubAttributes, "type").CanonicalValues)
+
+	password := findAttribute(s.Attributes, "password")
+	require.NotNil(t, password)
+	assert.Equal(t, WriteOnly, password.Mutability)
+	assert.Equal(t, Never, password.Returned)
+
+	_, ok = CoreSchema("urn:example:params:scim:schemas:core:2.0:Badge")
+	assert.False(t, ok)
+}
+
+func TestCoreSchemaCopies(t *testing.T) {
+	s, _ := CoreSchema(GroupURN)
+	s.Attributes[0].Name = "changed"
+	s.Attributes[1].SubAttributes[0].Mutability = ReadOnly
+
+	s, _ = CoreSchema(GroupURN)
+	assert.Equal(t, "displayName", s.Attributes[0].Name)
+	assert.Equal(t, Immutable, s.Attributes[1].SubAttributes[0].Mutability)
+}
+
+func TestValidateWithCoreSchemas(t *testing.T) {
+	u := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &u))
+	assert.NoError(t, Validate(&u, CoreSchemas()...))
+
+	u.Emails[1].Type = "office"
+	assert.EqualError(t, Validate(&u, CoreSchemas()...), `invalid User resource - emails[1].type: "office" is not one of the canonical values (work, home, other)`)
+
+	// The core schemas are themselves valid Schema resources.
+	for _, s := range CoreSchemas() {
+		s := s
+		assert.NoError(t, Validate(&s, CoreSchemas()...), s.ID)
+	}
+}
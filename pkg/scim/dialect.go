@@ -0,0 +1,248 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+)
+
+//
+// Error messages
+//
+
+const unknownDialectMessage = "unknown PATCH dialect"
+
+// Dialect identifies the variant of the PatchOp request body that a SCIM
+// server (or client) produces and expects.
+type Dialect string
+
+const (
+	// StandardDialect is the PatchOp as described by RFC7644 section 3.5.2.
+	StandardDialect Dialect = "standard"
+
+	// EntraIDDialect is the PatchOp produced and accepted by Microsoft
+	// Entra ID (formerly Azure Active Directory) provisioning - operations
+	// are capitalized ("Replace"), booleans are sent as the strings "True"
+	// and "False" and replace operations are combined into a single
+	// operation without a path whose value's keys are the attribute paths.
+	// https://learn.microsoft.com/en-us/entra/identity/app-provisioning/use-scim-to-provision-users-and-groups
+	EntraIDDialect Dialect = "entraid"
+)
+
+func (d Dialect) valid() bool {
+	return d == "" || d == StandardDialect || d == EntraIDDialect
+}
+
+// Encode returns the PatchOp rewritten in the dialect's form.  The
+// provided PatchOp is expected to be in the standard form and isn't
+// modified.
+func (d Dialect) Encode(po PatchOp) (PatchOp, error) {
+	if len(po.Schemas) == 0 {
+		po.Schemas = []string{PatchOpURN}
+	}
+	if d != EntraIDDialect {
+		return po, nil
+	}
+
+	ops := []PatchOperation{}
+	for _, op := range po.Operations {
+		value, err := generic(op.Value)
+		if err != nil {
+			return po, err
+		}
+		value = stringifyBooleans(value)
+
+		// Consecutive replace operations without value filters are
+		// combined into a single operation without a path.
+		if op.Op == PatchReplace && !strings.Contains(op.Path, "[") {
+			obj, ok := value.(map[string]interface{})
+			if op.Path != "" {
+				obj, ok = map[string]interface{}{op.Path: value}, true
+			}
+			if ok {
+				if last := len(ops) - 1; last >= 0 && ops[last].Op == capitalize(PatchReplace) && ops[last].Path == "" && disjoint(ops[last].Value, obj) {
+					for k, v := range obj {
+						ops[last].Value.(map[string]interface{})[k] = v
+					}
+					continue
+				}
+				ops = append(ops, PatchOperation{Op: capitalize(PatchReplace), Value: obj})
+				continue
+			}
+		}
+		ops = append(ops, PatchOperation{Op: capitalize(op.Op), Path: op.Path, Value: value})
+	}
+	po.Operations = ops
+	return po, nil
+}
+
+// Decode returns the PatchOp, which was produced in the dialect's form,
+// rewritten in the standard form.  Operation names are lowercased, the
+// values of add and replace operations without a path are split into one
+// operation per key (so an extension object becomes a single operation
+// whose path is the extension's URN) and the strings "True" and "False"
+// are converted to booleans where the provided schemas (or the core
+// schemas if none are provided) define the attribute as a Boolean.
+func (d Dialect) Decode(po PatchOp, schemas ...Schema) (PatchOp, error) {
+	if d != EntraIDDialect {
+		return po, nil
+	}
+	if len(schemas) == 0 {
+		schemas = CoreSchemas()
+	}
+
+	ops := []PatchOperation{}
+	for _, op := range po.Operations {
+		value, err := generic(op.Value)
+		if err != nil {
+			return po, err
+		}
+		typ := PatchOpType(strings.ToLower(string(op.Op)))
+
+		obj, ok := value.(map[string]interface{})
+		if op.Path != "" || typ == PatchRemove || !ok {
+			value = parseBooleans(value, pathAttribute(op.Path, schemas))
+			ops = append(ops, PatchOperation{Op: typ, Path: op.Path, Value: value})
+			continue
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			value := parseBooleans(obj[k], pathAttribute(k, schemas))
+			ops = append(ops, PatchOperation{Op: typ, Path: k, Value: value})
+		}
+	}
+	po.Operations = ops
+	return po, nil
+}
+
+// ParsePatchOp unmarshals the PatchOp request body, which was produced in
+// the client's dialect, and returns it in the standard form (see
+// Dialect.Decode) using the schemas of the SCIM server associated with the
+// provided context.
+func (c Client) ParsePatchOp(ctx context.Context, body []byte) (PatchOp, error) {
+	po := PatchOp{}
+	err := json.Unmarshal(body, &po)
+	if err != nil {
+		return po, CodecError{
+			Err:  err.Error(),
+			Op:   Unmarshal,
+			Body: body,
+		}
+	}
+	schemas, err := c.schemas(ctx)
+	if err != nil {
+		return po, err
+	}
+	return c.cfg.PatchDialect.Decode(po, schemas...)
+}
+
+// disjoint indicates whether none of the object's keys are also keys of
+// the combined operation's value.
+func disjoint(combined interface{}, obj map[string]interface{}) bool {
+	for k := range obj {
+		if lookup(combined.(map[string]interface{}), k) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func capitalize(op PatchOpType) PatchOpType {
+	s := string(op)
+	if s == "" {
+		return op
+	}
+	return PatchOpType(strings.ToUpper(s[:1]) + s[1:])
+}
+
+func stringifyBooleans(v interface{}) interface{} {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return "True"
+		}
+		return "False"
+	case []interface{}:
+		for i := range val {
+			val[i] = stringifyBooleans(val[i])
+		}
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = stringifyBooleans(val[k])
+		}
+	}
+	return v
+}
+
+// parseBooleans converts the strings "True" and "False" to booleans if the
+// attribute (or, for complex values, the sub-attribute) is a Boolean.
+func parseBooleans(v interface{}, attr *Attribute) interface{} {
+	if attr == nil {
+		return v
+	}
+	switch val := v.(type) {
+	case string:
+		if attr.Type != Boolean {
+			return v
+		}
+		switch val {
+		case "True":
+			return true
+		case "False":
+			return false
+		}
+	case []interface{}:
+		for i := range val {
+			val[i] = parseBooleans(val[i], attr)
+		}
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = parseBooleans(val[k], findAttribute(attr.SubAttributes, k))
+		}
+	}
+	return v
+}
+
+// pathAttribute returns the definition of the attribute identified by
+// the path or nil if the path is invalid or the schemas don't define it.
+// A path without a URN matches the attributes of any of the schemas and
+// an extension's URN matches the extension as a whole.
+func pathAttribute(path string, schemas []Schema) *Attribute {
+	p, err := filter.ParsePath(path)
+	if err != nil {
+		return nil
+	}
+	if urn, whole := extensionPath(p); whole {
+		for _, s := range schemas {
+			if strings.EqualFold(s.ID, urn) {
+				return &Attribute{Name: s.ID, Type: Complex, SubAttributes: s.Attributes}
+			}
+		}
+	}
+	for _, s := range schemas {
+		if p.URI != "" && !strings.EqualFold(s.ID, p.URI) {
+			continue
+		}
+		attr := findAttribute(s.Attributes, p.Name)
+		if attr == nil {
+			continue
+		}
+		if p.SubAttribute != "" {
+			return findAttribute(attr.SubAttributes, p.SubAttribute)
+		}
+		return attr
+	}
+	return nil
+}
+
+func invalidDialect(d Dialect) error {
+	return fmt.Errorf("%s: %q", unknownDialectMessage, d)
+}
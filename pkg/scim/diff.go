@@ -0,0 +1,301 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+)
+
+const mismatchedResourcesMessage = "can't compare resources with different schemas"
+
+// Diff returns the PATCH operations that transform the from resource
+// (typically the server's copy) into the to resource (the desired copy).
+//
+// Singular complex attributes are compared sub-attribute by sub-attribute
+// and multi-valued complex attributes value by value - values are
+// identified by their "value" sub-attribute or, if they have none, by
+// their "type".  Removed values are removed using a value filter (e.g.
+// emails[value eq "babs@example.com"]), changed values have their
+// sub-attributes replaced and new values are added.  Multi-valued
+// attributes whose values can't be identified are replaced.  Extension
+// attributes are compared using their fully qualified paths.
+//
+// The id, schemas and meta attributes as well as those defined as readOnly
+// by the provided schemas (or by the core schemas if none are provided)
+// are ignored.  Operations are ordered by
+// attribute (core attributes before extensions) and, for each attribute,
+// removals precede replacements and additions.
+func Diff(from, to Resource, schemas ...Schema) (PatchOp, error) {
+	if !strings.EqualFold(from.URN(), to.URN()) {
+		return PatchOp{}, errors.New(mismatchedResourcesMessage)
+	}
+	fromDoc, err := document(from)
+	if err != nil {
+		return PatchOp{}, err
+	}
+	toDoc, err := document(to)
+	if err != nil {
+		return PatchOp{}, err
+	}
+
+	if len(schemas) == 0 {
+		schemas = CoreSchemas()
+	}
+	d := differ{evaluator: newEvaluator(to, schemas), ops: []PatchOperation{}}
+	fromExts, toExts := extensions(fromDoc), extensions(toDoc)
+	d.object(fromDoc, toDoc, d.root(toDoc).attrs, "")
+
+	urns := make([]string, 0, len(fromExts)+len(toExts))
+	for urn := range toExts {
+		urns = append(urns, urn)
+	}
+	for urn := range fromExts {
+		if _, ok := toExts[urn]; !ok {
+			urns = append(urns, urn)
+		}
+	}
+	sort.Strings(urns)
+	for _, urn := range urns {
+		d.object(fromExts[urn], toExts[urn], d.schemas[strings.ToLower(urn)].Attributes, urn+":")
+	}
+
+	return NewPatchOp(d.ops...), nil
+}
+
+// extensions removes the extension objects from the document and returns
+// them keyed by their URNs.
+func extensions(doc map[string]interface{}) map[string]map[string]interface{} {
+	exts := map[string]map[string]interface{}{}
+	for k, v := range doc {
+		if !strings.HasPrefix(strings.ToLower(k), "urn:") {
+			continue
+		}
+		if obj, ok := v.(map[string]interface{}); ok {
+			exts[k] = obj
+		}
+		delete(doc, k)
+	}
+	return exts
+}
+
+type differ struct {
+	evaluator
+	ops []PatchOperation
+}
+
+// ignored lists the attributes that are never included in a diff.
+var ignored = map[string]bool{"id": true, "schemas": true, "meta": true}
+
+// object compares the attributes of two objects - prefix is prepended to
+// each attribute's name to form its path.
+func (d *differ) object(from, to map[string]interface{}, attrs []Attribute, prefix string) {
+	names := map[string]string{}
+	for _, obj := range []map[string]interface{}{from, to} {
+		for k := range obj {
+			names[strings.ToLower(k)] = k
+		}
+	}
+	keys := make([]string, 0, len(names))
+	for _, k := range names {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, name := range keys {
+		if prefix == "" && ignored[strings.ToLower(name)] {
+			continue
+		}
+		attr := findAttribute(attrs, name)
+		if attr != nil && attr.Mutability == ReadOnly {
+			continue
+		}
+		d.attribute(prefix+name, lookup(from, name), lookup(to, name), attr)
+	}
+}
+
+// attribute compares the values of a single attribute.
+func (d *differ) attribute(path string, from, to interface{}, attr *Attribute) {
+	if isEmpty(to) {
+		to = nil
+	}
+	if isEmpty(from) {
+		from = nil
+	}
+	switch {
+	case reflect.DeepEqual(from, to):
+		return
+	case to == nil:
+		if _, ok := from.(bool); ok {
+			// Encoders omit false booleans so a missing boolean is false.
+			d.op(PatchReplace, path, false)
+			return
+		}
+		d.op(PatchRemove, path, nil)
+		return
+	case from == nil:
+		d.op(PatchAdd, path, to)
+		return
+	}
+
+	fromArr, fromMulti := from.([]interface{})
+	toArr, toMulti := to.([]interface{})
+	fromObj, fromComplex := from.(map[string]interface{})
+	toObj, toComplex := to.(map[string]interface{})
+	switch {
+	case fromMulti && toMulti:
+		d.multiValued(path, fromArr, toArr, attr)
+	case fromComplex && toComplex:
+		var subs []Attribute
+		if attr != nil {
+			subs = attr.SubAttributes
+		}
+		d.subAttributes(path, fromObj, toObj, subs, "")
+	default:
+		d.op(PatchReplace, path, to)
+	}
+}
+
+// subAttributes compares the sub-attributes of two complex values.
+// selector, if provided, is the path that selects the multi-valued
+// attribute's value.
+func (d *differ) subAttributes(path string, from, to map[string]interface{}, subs []Attribute, selector string) {
+	names := map[string]string{}
+	for _, obj := range []map[string]interface{}{from, to} {
+		for k := range obj {
+			names[strings.ToLower(k)] = k
+		}
+	}
+	keys := make([]string, 0, len(names))
+	for _, k := range names {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	removes, replaces := []PatchOperation{}, []PatchOperation{}
+	for _, name := range keys {
+		sub := findAttribute(subs, name)
+		if sub != nil && sub.Mutability == ReadOnly {
+			continue
+		}
+		f, t := lookup(from, name), lookup(to, name)
+		if isEmpty(f) {
+			f = nil
+		}
+		if isEmpty(t) {
+			t = nil
+		}
+		p := path + "." + name
+		if selector != "" {
+			p = selector + "." + name
+		}
+		switch {
+		case reflect.DeepEqual(f, t):
+		case t == nil:
+			if _, ok := f.(bool); ok {
+				replaces = append(replaces, PatchOperation{Op: PatchReplace, Path: p, Value: false})
+				continue
+			}
+			removes = append(removes, PatchOperation{Op: PatchRemove, Path: p})
+		default:
+			replaces = append(replaces, PatchOperation{Op: PatchReplace, Path: p, Value: t})
+		}
+	}
+	d.ops = append(d.ops, removes...)
+	d.ops = append(d.ops, replaces...)
+}
+
+// multiValued compares the values of a multi-valued attribute.
+func (d *differ) multiValued(path string, from, to []interface{}, attr *Attribute) {
+	key := valueKey(from, to)
+	if key == "" {
+		d.op(PatchReplace, path, to)
+		return
+	}
+	var subs []Attribute
+	if attr != nil {
+		subs = attr.SubAttributes
+	}
+
+	fromByKey := map[interface{}]map[string]interface{}{}
+	for _, v := range from {
+		obj := v.(map[string]interface{})
+		fromByKey[lookup(obj, key)] = obj
+	}
+	toKeys := map[interface{}]bool{}
+	for _, v := range to {
+		toKeys[lookup(v.(map[string]interface{}), key)] = true
+	}
+
+	for _, v := range from {
+		k := lookup(v.(map[string]interface{}), key)
+		if !toKeys[k] {
+			d.op(PatchRemove, valuePath(path, key, k), nil)
+		}
+	}
+	added := []interface{}{}
+	for _, v := range to {
+		obj := v.(map[string]interface{})
+		k := lookup(obj, key)
+		prev, ok := fromByKey[k]
+		if !ok {
+			added = append(added, obj)
+			continue
+		}
+		d.subAttributes(path, prev, obj, subs, valuePath(path, key, k))
+	}
+	if len(added) > 0 {
+		d.op(PatchAdd, path, added)
+	}
+}
+
+// valueKey returns the sub-attribute ("value" or "type") that uniquely
+// identifies every value of both multi-valued attributes or an empty
+// string if there is no such sub-attribute.
+func valueKey(from, to []interface{}) string {
+	for _, key := range []string{"value", "type"} {
+		if uniquelyKeyed(from, key) && uniquelyKeyed(to, key) {
+			return key
+		}
+	}
+	return ""
+}
+
+func uniquelyKeyed(values []interface{}, key string) bool {
+	seen := map[interface{}]bool{}
+	for _, v := range values {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		k := lookup(obj, key)
+		switch k.(type) {
+		case string, bool, json.Number:
+		default:
+			return false
+		}
+		if isEmpty(k) || seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return true
+}
+
+// valuePath returns the path that selects a single value of a
+// multi-valued attribute - e.g. emails[value eq "babs@example.com"].
+func valuePath(path string, key string, value interface{}) string {
+	p := filter.Path{Name: path, Filter: filter.AttributeExpression{
+		Path:     filter.AttributePath{Name: key},
+		Operator: filter.Eq,
+		Value:    value,
+	}}
+	return p.String()
+}
+
+func (d *differ) op(op PatchOpType, path string, value interface{}) {
+	d.ops = append(d.ops, PatchOperation{Op: op, Path: path, Value: value})
+}
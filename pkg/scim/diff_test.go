@@ -0,0 +1,171 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	from := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &from))
+
+	tests := []struct {
+		name   string
+		mutate func(*User)
+		exp    []PatchOperation
+	}{
+		{"No changes", func(u *User) {}, []PatchOperation{}},
+		{"Ignored attributes", func(u *User) {
+			u.ID = "other"
+			u.Meta.Version = "W/\"4\""
+			u.Schemas = []string{UserURN}
+		}, []PatchOperation{}},
+		{"Replace", func(u *User) { u.Title = "Senior Tour Guide" }, []PatchOperation{
+			{Op: PatchReplace, Path: "title", Value: "Senior Tour Guide"},
+		}},
+		{"Add", func(u *User) { u.NickName = "Babs" }, []PatchOperation{
+			{Op: PatchAdd, Path: "nickName", Value: "Babs"},
+		}},
+		{"Remove", func(u *User) { u.UserType = "" }, []PatchOperation{
+			{Op: PatchRemove, Path: "userType"},
+		}},
+		{"False boolean", func(u *User) { u.Active = false }, []PatchOperation{
+			{Op: PatchReplace, Path: "active", Value: false},
+		}},
+		{"Sub-attributes", func(u *User) {
+			u.Name = &Name{GivenName: "Babs", MiddleName: "J"}
+		}, []PatchOperation{
+			{Op: PatchRemove, Path: "name.familyName"},
+			{Op: PatchReplace, Path: "name.givenName", Value: "Babs"},
+			{Op: PatchReplace, Path: "name.middleName", Value: "J"},
+		}},
+		{"Multi-valued", func(u *User) {
+			u.Emails = []Email{
+				{Value: "bjensen@example.com", Multivalued: Multivalued{Type: "other"}},
+				{Value: "barbara@example.org", Multivalued: Multivalued{Type: "home"}},
+			}
+		}, []PatchOperation{
+			{Op: PatchRemove, Path: `emails[value eq "babs@jensen.org"]`},
+			{Op: PatchReplace, Path: `emails[value eq "bjensen@example.com"].primary`, Value: false},
+			{Op: PatchReplace, Path: `emails[value eq "bjensen@example.com"].type`, Value: "other"},
+			{Op: PatchAdd, Path: "emails", Value: []interface{}{
+				map[string]interface{}{"value": "barbara@example.org", "type": "home"},
+			}},
+		}},
+		{"Multi-valued removed", func(u *User) { u.Emails = nil }, []PatchOperation{
+			{Op: PatchRemove, Path: "emails"},
+		}},
+		{"Extension", func(u *User) {
+			eu := EnterpriseUser{}
+			require.NoError(t, u.GetExtension(&eu))
+			eu.CostCenter = ""
+			eu.Department = "Tour Operations"
+			eu.Manager.Value = "62f9b4d4"
+			require.NoError(t, u.UpdateExtension(&eu))
+		}, []PatchOperation{
+			{Op: PatchRemove, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:costCenter"},
+			{Op: PatchAdd, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Value: "Tour Operations"},
+			{Op: PatchReplace, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value", Value: "62f9b4d4"},
+		}},
+		{"Extension removed", func(u *User) {
+			u.AdditionalProperties = nil
+		}, []PatchOperation{
+			{Op: PatchRemove, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:costCenter"},
+			{Op: PatchRemove, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber"},
+			{Op: PatchRemove, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager"},
+		}},
+		{"Ordered by attribute", func(u *User) {
+			u.UserType = "Contractor"
+			u.DisplayName = "Babs Jensen"
+			u.ExternalID = "701985"
+		}, []PatchOperation{
+			{Op: PatchAdd, Path: "displayName", Value: "Babs Jensen"},
+			{Op: PatchReplace, Path: "externalId", Value: "701985"},
+			{Op: PatchReplace, Path: "userType", Value: "Contractor"},
+		}},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			to := User{}
+			require.NoError(t, json.Unmarshal([]byte(evaluateUser), &to))
+			test.mutate(&to)
+
+			act, err := Diff(&from, &to)
+			require.NoError(t, err)
+			assert.Equal(t, []string{PatchOpURN}, act.Schemas)
+			assert.Equal(t, test.exp, act.Operations)
+		})
+	}
+}
+
+func TestDiffReadOnly(t *testing.T) {
+	schemas := []Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "title", Type: String},
+			{Name: "groups", Type: Complex, Multivalued: true, Mutability: ReadOnly},
+			{Name: "emails", Type: Complex, Multivalued: true, SubAttributes: []Attribute{
+				{Name: "display", Type: String, Mutability: ReadOnly},
+				{Name: "value", Type: String},
+			}},
+		},
+	}}
+	from := User{Title: "Tour Guide", Emails: []Email{{Value: "bjensen@example.com"}}}
+	to := User{
+		Title:  "Senior Tour Guide",
+		Groups: []GroupRef{{Value: "e9e30dba", Multivalued: Multivalued{Display: "Tour Guides"}}},
+		Emails: []Email{{Value: "bjensen@example.com", Multivalued: Multivalued{Display: "Babs"}}},
+	}
+
+	act, err := Diff(&from, &to, schemas...)
+	require.NoError(t, err)
+	assert.Equal(t, []PatchOperation{
+		{Op: PatchReplace, Path: "title", Value: "Senior Tour Guide"},
+	}, act.Operations)
+}
+
+func TestDiffWithoutSchemas(t *testing.T) {
+	from := User{Title: "Tour Guide"}
+	to := User{
+		Title:  "Senior Tour Guide",
+		Groups: []GroupRef{{Value: "e9e30dba", Multivalued: Multivalued{Display: "Tour Guides"}}},
+	}
+
+	act, err := Diff(&from, &to)
+	require.NoError(t, err)
+	assert.Equal(t, []PatchOperation{
+		{Op: PatchReplace, Path: "title", Value: "Senior Tour Guide"},
+	}, act.Operations)
+}
+
+func TestDiffUnkeyedValues(t *testing.T) {
+	from := Group{Members: []MemberRef{{Value: "a"}, {Value: "b"}}}
+	to := Group{Members: []MemberRef{{Value: "b"}, {Value: "c"}}}
+	act, err := Diff(&from, &to)
+	require.NoError(t, err)
+	assert.Equal(t, []PatchOperation{
+		{Op: PatchRemove, Path: `members[value eq "a"]`},
+		{Op: PatchAdd, Path: "members", Value: []interface{}{map[string]interface{}{"value": "c"}}},
+	}, act.Operations)
+
+	// Duplicate values can't be identified so the attribute is replaced.
+	to = Group{Members: []MemberRef{{Value: "b"}, {Value: "b"}}}
+	act, err = Diff(&from, &to)
+	require.NoError(t, err)
+	assert.Equal(t, []PatchOperation{
+		{Op: PatchReplace, Path: "members", Value: []interface{}{
+			map[string]interface{}{"value": "b"},
+			map[string]interface{}{"value": "b"},
+		}},
+	}, act.Operations)
+}
+
+func TestDiffMismatchedResources(t *testing.T) {
+	_, err := Diff(&User{}, &Group{})
+	assert.EqualError(t, err, mismatchedResourcesMessage)
+}
@@ -0,0 +1,115 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ETag is an HTTP entity-tag as used by SCIM servers to version resources.
+// SCIM servers report a resource's ETag in both the ETag response header
+// and the resource's meta.version attribute.
+// https://tools.ietf.org/html/rfc7644#section-3.14
+// https://tools.ietf.org/html/rfc7232#section-2.3
+type ETag struct {
+	Tag  string //Tag is the entity-tag's opaque-tag without the surrounding quotes.
+	Weak bool   //Weak indicates that the entity-tag was prefixed with the W/ weakness indicator.
+	Any  bool   //Any indicates the "*" wildcard which matches any current representation.
+}
+
+// AnyETag is the If-Match wildcard that matches any current representation
+// of a resource.
+var AnyETag = ETag{Any: true}
+
+// InvalidETagError is returned when a string can't be parsed as an
+// entity-tag.
+type InvalidETagError struct {
+	Value  string
+	Reason string
+}
+
+func (iee InvalidETagError) Error() string {
+	return fmt.Sprintf("invalid entity-tag %q: %s", iee.Value, iee.Reason)
+}
+
+// ParseETag parses the provided entity-tag.  Since many SCIM servers
+// render meta.version without the quotes required by RFC7232 (or with a
+// JSON-escaped "W\/" prefix), ParseETag accepts those forms as well.  An
+// empty string results in the zero ETag.
+func ParseETag(s string) (ETag, error) {
+	v := strings.TrimSpace(s)
+	if v == "" {
+		return ETag{}, nil
+	}
+	if v == "*" {
+		return AnyETag, nil
+	}
+
+	etag := ETag{}
+	for _, prefix := range []string{"W/", "W\\/"} {
+		if strings.HasPrefix(v, prefix) {
+			etag.Weak = true
+			v = v[len(prefix):]
+			break
+		}
+	}
+
+	if strings.HasPrefix(v, "\"") || strings.HasSuffix(v, "\"") {
+		if len(v) < 2 || !strings.HasPrefix(v, "\"") || !strings.HasSuffix(v, "\"") {
+			return ETag{}, InvalidETagError{Value: s, Reason: "unbalanced quotes"}
+		}
+		v = v[1 : len(v)-1]
+	}
+	if v == "" {
+		return ETag{}, InvalidETagError{Value: s, Reason: "empty opaque-tag"}
+	}
+
+	// etagc = %x21 / %x23-7E / obs-text
+	for _, r := range v {
+		if r == '"' || r <= 0x20 || r == 0x7f {
+			return ETag{}, InvalidETagError{Value: s, Reason: fmt.Sprintf("illegal character %q", r)}
+		}
+	}
+	etag.Tag = v
+	return etag, nil
+}
+
+// IsZero indicates whether the ETag is empty (and should therefore not be
+// sent in a precondition header).
+func (e ETag) IsZero() bool {
+	return !e.Any && e.Tag == ""
+}
+
+// String returns the entity-tag formatted as required by the ETag and
+// If-Match headers.
+func (e ETag) String() string {
+	switch {
+	case e.Any:
+		return "*"
+	case e.Tag == "":
+		return ""
+	case e.Weak:
+		return "W/\"" + e.Tag + "\""
+	default:
+		return "\"" + e.Tag + "\""
+	}
+}
+
+// StrongMatch implements the strong comparison function - both entity-tags
+// must be strong and their opaque-tags identical.
+// https://tools.ietf.org/html/rfc7232#section-2.3.2
+func (e ETag) StrongMatch(o ETag) bool {
+	if e.Any || o.Any {
+		return !e.IsZero() && !o.IsZero()
+	}
+	return !e.Weak && !o.Weak && e.Tag != "" && e.Tag == o.Tag
+}
+
+// WeakMatch implements the weak comparison function - the opaque-tags must
+// be identical regardless of either entity-tag's weakness.
+// https://tools.ietf.org/html/rfc7232#section-2.3.2
+func (e ETag) WeakMatch(o ETag) bool {
+	if e.Any || o.Any {
+		return !e.IsZero() && !o.IsZero()
+	}
+	return e.Tag != "" && e.Tag == o.Tag
+}
@@ -0,0 +1,66 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseETag(t *testing.T) {
+	tests := []struct {
+		name string
+		inp  string
+		exp  ETag
+		str  string
+		err  bool
+	}{
+		{"Empty", "", ETag{}, "", false},
+		{"Wildcard", "*", AnyETag, "*", false},
+		{"Strong", "\"xyzzy\"", ETag{Tag: "xyzzy"}, "\"xyzzy\"", false},
+		{"Weak", "W/\"xyzzy\"", ETag{Tag: "xyzzy", Weak: true}, "W/\"xyzzy\"", false},
+		{"Weak JSON escaped", "W\\/\"xyzzy\"", ETag{Tag: "xyzzy", Weak: true}, "W/\"xyzzy\"", false},
+		{"Weak unquoted", "W/a330bc54f0671c9", ETag{Tag: "a330bc54f0671c9", Weak: true}, "W/\"a330bc54f0671c9\"", false},
+		{"Unquoted", "a330bc54f0671c9", ETag{Tag: "a330bc54f0671c9"}, "\"a330bc54f0671c9\"", false},
+		{"Unbalanced quotes", "\"xyzzy", ETag{}, "", true},
+		{"Empty opaque-tag", "W/\"\"", ETag{}, "", true},
+		{"Embedded space", "\"xy zzy\"", ETag{}, "", true},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := ParseETag(test.inp)
+			if test.err {
+				assert.IsType(t, InvalidETagError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.exp, act)
+			assert.Equal(t, test.str, act.String())
+		})
+	}
+}
+
+func TestETagComparison(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   ETag
+		strong bool
+		weak   bool
+	}{
+		{"W/\"1\" and W/\"1\"", ETag{Tag: "1", Weak: true}, ETag{Tag: "1", Weak: true}, false, true},
+		{"W/\"1\" and W/\"2\"", ETag{Tag: "1", Weak: true}, ETag{Tag: "2", Weak: true}, false, false},
+		{"W/\"1\" and \"1\"", ETag{Tag: "1", Weak: true}, ETag{Tag: "1"}, false, true},
+		{"\"1\" and \"1\"", ETag{Tag: "1"}, ETag{Tag: "1"}, true, true},
+		{"* and \"1\"", AnyETag, ETag{Tag: "1"}, true, true},
+		{"* and empty", AnyETag, ETag{}, false, false},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.strong, test.a.StrongMatch(test.b))
+			assert.Equal(t, test.weak, test.a.WeakMatch(test.b))
+		})
+	}
+}
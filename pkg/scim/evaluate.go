@@ -0,0 +1,453 @@
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+)
+
+//
+// Error messages
+//
+
+const (
+	unsupportedComparisonMessage = "comparison is not supported"
+	unknownExpressionMessage     = "unknown filter expression"
+)
+
+// commonAttributes describes the schemas attribute (RFC7643 section 3)
+// and the attributes defined by RFC7643 section 3.1 that are part of every
+// resource but aren't included in the resource's schema.
+var commonAttributes = []Attribute{
+	{Name: "schemas", Type: Reference, Multivalued: true, CaseExact: true},
+	{Name: "id", Type: String, CaseExact: true, Mutability: ReadOnly, Returned: Always, Uniqueness: Server},
+	{Name: "externalId", Type: String, CaseExact: true, Mutability: ReadWrite},
+	{Name: "meta", Type: Complex, Mutability: ReadOnly, SubAttributes: []Attribute{
+		{Name: "resourceType", Type: String, CaseExact: true, Mutability: ReadOnly},
+		{Name: "created", Type: DateTime, Mutability: ReadOnly},
+		{Name: "lastModified", Type: DateTime, Mutability: ReadOnly},
+		{Name: "location", Type: Reference, CaseExact: true, Mutability: ReadOnly},
+		{Name: "version", Type: String, CaseExact: true, Mutability: ReadOnly},
+	}},
+}
+
+// Match reports whether the provided resource matches the filter.  The
+// schemas (core and extensions) are used to determine the type and
+// case-sensitivity of each attribute - attributes without a definition
+// are compared case-insensitively and their type is inferred from their
+// JSON value.  Attribute names are always matched case-insensitively, the
+// attributes of extensions are identified by their schema URN and a
+// multi-valued attribute matches if any of its values match.
+// https://tools.ietf.org/html/rfc7644#section-3.4.2.2
+func Match(expr filter.Expression, res Resource, schemas ...Schema) (bool, error) {
+	doc, err := document(res)
+	if err != nil {
+		return false, err
+	}
+	e := newEvaluator(res, schemas)
+	return e.match(expr, e.root(doc))
+}
+
+// Select returns the resources that match the provided filter (see Match).
+func Select(expr filter.Expression, resources []Resource, schemas ...Schema) ([]Resource, error) {
+	matches := []Resource{}
+	for _, res := range resources {
+		ok, err := Match(expr, res, schemas...)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, res)
+		}
+	}
+	return matches, nil
+}
+
+// document returns the resource's JSON representation as a generic map.
+// The Go zero values that the codecs emit for unassigned meta.created and
+// meta.lastModified dates are removed so that they behave as unassigned
+// attributes.
+func document(res Resource) (map[string]interface{}, error) {
+	b, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	delete(doc, "*")
+	pruneMeta(doc)
+	return doc, nil
+}
+
+func pruneMeta(doc map[string]interface{}) {
+	meta, ok := doc["meta"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	zero := (time.Time{}).Format(time.RFC3339)
+	for _, k := range []string{"created", "lastModified"} {
+		if meta[k] == zero {
+			delete(meta, k)
+		}
+	}
+}
+
+//
+// Evaluation
+//
+
+type evaluator struct {
+	urn        string
+	extensions []SchemaExtension
+	schemas    map[string]Schema
+}
+
+// scope is the JSON object that the attribute paths of an expression are
+// resolved against along with the definitions of its attributes.
+type scope struct {
+	obj   map[string]interface{}
+	attrs []Attribute
+	root  bool
+}
+
+func newEvaluator(res Resource, schemas []Schema) evaluator {
+	e := evaluator{
+		urn:        res.URN(),
+		extensions: res.ResourceType().SchemaExtensions,
+		schemas:    make(map[string]Schema, len(schemas)),
+	}
+	for _, s := range schemas {
+		e.schemas[strings.ToLower(s.ID)] = s
+	}
+	return e
+}
+
+func (e evaluator) root(doc map[string]interface{}) scope {
+	attrs := append([]Attribute{}, commonAttributes...)
+	attrs = append(attrs, e.schemas[strings.ToLower(e.urn)].Attributes...)
+	return scope{obj: doc, attrs: attrs, root: true}
+}
+
+func (e evaluator) match(expr filter.Expression, s scope) (bool, error) {
+	switch ex := expr.(type) {
+	case filter.LogicalExpression:
+		left, err := e.match(ex.Left, s)
+		if err != nil {
+			return false, err
+		}
+		if left == (ex.Operator == filter.Or) {
+			return left, nil
+		}
+		return e.match(ex.Right, s)
+	case filter.NotExpression:
+		ok, err := e.match(ex.Expression, s)
+		return !ok, err
+	case filter.ValuePathExpression:
+		values, attr := e.resolve(s, filter.AttributePath{URI: ex.Path.URI, Name: ex.Path.Name})
+		var subs []Attribute
+		if attr != nil {
+			subs = attr.SubAttributes
+		}
+		for _, v := range values {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ok, err := e.match(ex.Filter, scope{obj: obj, attrs: subs})
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case filter.AttributeExpression:
+		values, attr := e.resolve(s, ex.Path)
+		if attr != nil && attr.Type == Complex && ex.Operator != filter.Pr {
+			// Complex attributes are compared using their "value"
+			// sub-attribute - e.g. emails co "example.com".
+			if len(attr.SubAttributes) > 0 && findAttribute(attr.SubAttributes, "value") == nil {
+				return false, comparisonError(ex, "complex")
+			}
+			values, attr = subValues(values, attr, "value")
+		}
+		return compareAll(ex, values, attr)
+	}
+	return false, fmt.Errorf("%s: %T", unknownExpressionMessage, expr)
+}
+
+// resolve returns the non-empty values of the attribute identified by the
+// provided path along with the attribute's definition (or nil if the
+// attribute isn't defined by the schemas).
+func (e evaluator) resolve(s scope, path filter.AttributePath) ([]interface{}, *Attribute) {
+	obj, attrs := s.obj, s.attrs
+	if path.URI != "" && s.root && !strings.EqualFold(path.URI, e.urn) {
+		ext, _ := lookup(obj, path.URI).(map[string]interface{})
+		obj, attrs = ext, e.schemas[strings.ToLower(path.URI)].Attributes
+	}
+
+	attr := findAttribute(attrs, path.Name)
+	values := flatten(lookup(obj, path.Name))
+	if path.SubAttribute != "" {
+		values, attr = subValues(values, attr, path.SubAttribute)
+	}
+	if attr == nil && len(values) > 0 {
+		// Complex values without a definition are still compared using
+		// their "value" sub-attribute.
+		if _, ok := values[0].(map[string]interface{}); ok {
+			attr = &Attribute{Name: path.Name, Type: Complex}
+		}
+	}
+	return values, attr
+}
+
+func subValues(values []interface{}, attr *Attribute, name string) ([]interface{}, *Attribute) {
+	subs := []interface{}{}
+	for _, v := range values {
+		if obj, ok := v.(map[string]interface{}); ok {
+			subs = append(subs, flatten(lookup(obj, name))...)
+		}
+	}
+	if attr == nil {
+		return subs, nil
+	}
+	return subs, findAttribute(attr.SubAttributes, name)
+}
+
+// lookup returns the value of the object's key that case-insensitively
+// matches the provided name.
+func lookup(obj map[string]interface{}, name string) interface{} {
+	if v, ok := obj[name]; ok {
+		return v
+	}
+	for k, v := range obj {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// flatten returns the values of a multi-valued attribute (or the single
+// value of a singular attribute) omitting any that are unassigned.
+func flatten(v interface{}) []interface{} {
+	values := []interface{}{}
+	if arr, ok := v.([]interface{}); ok {
+		for _, av := range arr {
+			values = append(values, flatten(av)...)
+		}
+		return values
+	}
+	if !isEmpty(v) {
+		values = append(values, v)
+	}
+	return values
+}
+
+func isEmpty(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case map[string]interface{}:
+		for _, sv := range val {
+			if !isEmpty(sv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(flatten(val)) == 0
+	}
+	return false
+}
+
+func findAttribute(attrs []Attribute, name string) *Attribute {
+	for idx := range attrs {
+		if strings.EqualFold(attrs[idx].Name, name) {
+			return &attrs[idx]
+		}
+	}
+	return nil
+}
+
+//
+// Comparison
+//
+
+func compareAll(ex filter.AttributeExpression, values []interface{}, attr *Attribute) (bool, error) {
+	if attr != nil && attr.Type == Boolean && ex.Operator != filter.Eq && ex.Operator != filter.Ne && ex.Operator != filter.Pr {
+		return false, comparisonError(ex, "boolean")
+	}
+	switch {
+	case ex.Operator == filter.Pr:
+		return len(values) > 0, nil
+	case ex.Value == nil && ex.Operator == filter.Eq:
+		return len(values) == 0, nil
+	case ex.Value == nil && ex.Operator == filter.Ne:
+		return len(values) > 0, nil
+	case ex.Value == nil:
+		return false, comparisonError(ex, "null")
+	case len(values) == 0:
+		return ex.Operator == filter.Ne, nil
+	}
+
+	for _, v := range values {
+		ok, err := compare(ex, v, attr)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func compare(ex filter.AttributeExpression, v interface{}, attr *Attribute) (bool, error) {
+	if attr != nil && attr.Type == Boolean {
+		if _, ok := v.(bool); !ok {
+			return false, nil
+		}
+	}
+	switch val := v.(type) {
+	case bool:
+		b, ok := ex.Value.(bool)
+		switch ex.Operator {
+		case filter.Eq:
+			return ok && b == val, nil
+		case filter.Ne:
+			return !ok || b != val, nil
+		}
+		return false, comparisonError(ex, "boolean")
+	case json.Number:
+		n, ok := ex.Value.(json.Number)
+		if !ok {
+			return ex.Operator == filter.Ne, nil
+		}
+		return compareNumbers(ex, val, n)
+	case string:
+		s, ok := ex.Value.(string)
+		if !ok {
+			return ex.Operator == filter.Ne, nil
+		}
+		if t, u, ok := times(val, s, attr); ok {
+			return compareTimes(ex, t, u)
+		}
+		return compareStrings(ex, val, s, attr != nil && attr.CaseExact)
+	}
+	return false, comparisonError(ex, "complex")
+}
+
+// compareNumbers compares the numbers as integers if both are (so that
+// large integers don't lose precision) or as floats otherwise.
+func compareNumbers(ex filter.AttributeExpression, v, n json.Number) (bool, error) {
+	c := 0
+	i, ierr := v.Int64()
+	j, jerr := n.Int64()
+	if ierr == nil && jerr == nil {
+		if i < j {
+			c = -1
+		} else if i > j {
+			c = 1
+		}
+	} else {
+		a, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return false, err
+		}
+		b, err := strconv.ParseFloat(n.String(), 64)
+		if err != nil {
+			return false, err
+		}
+		if a < b {
+			c = -1
+		} else if a > b {
+			c = 1
+		}
+	}
+	ok, supported := ordered(ex.Operator, c)
+	if !supported {
+		return false, comparisonError(ex, "number")
+	}
+	return ok, nil
+}
+
+func compareTimes(ex filter.AttributeExpression, t, u time.Time) (bool, error) {
+	c := 0
+	if t.Before(u) {
+		c = -1
+	} else if t.After(u) {
+		c = 1
+	}
+	ok, supported := ordered(ex.Operator, c)
+	if !supported {
+		return false, comparisonError(ex, "dateTime")
+	}
+	return ok, nil
+}
+
+func compareStrings(ex filter.AttributeExpression, v, s string, caseExact bool) (bool, error) {
+	if !caseExact {
+		v, s = strings.ToLower(v), strings.ToLower(s)
+	}
+	switch ex.Operator {
+	case filter.Co:
+		return strings.Contains(v, s), nil
+	case filter.Sw:
+		return strings.HasPrefix(v, s), nil
+	case filter.Ew:
+		return strings.HasSuffix(v, s), nil
+	}
+	ok, _ := ordered(ex.Operator, strings.Compare(v, s))
+	return ok, nil
+}
+
+// ordered returns the result of the provided operator given the result
+// of comparing two values (-1, 0 or 1) and a boolean indicating whether
+// the operator is an equality or ordering operator.
+func ordered(op filter.CompareOperator, c int) (bool, bool) {
+	switch op {
+	case filter.Eq:
+		return c == 0, true
+	case filter.Ne:
+		return c != 0, true
+	case filter.Gt:
+		return c > 0, true
+	case filter.Ge:
+		return c >= 0, true
+	case filter.Lt:
+		return c < 0, true
+	case filter.Le:
+		return c <= 0, true
+	}
+	return false, false
+}
+
+// times parses both strings as dateTimes if the attribute is defined as a
+// dateTime or, for undefined attributes, if both strings are valid
+// dateTimes.
+func times(v, s string, attr *Attribute) (time.Time, time.Time, bool) {
+	if attr != nil && attr.Type != DateTime {
+		return time.Time{}, time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	u, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return t, u, true
+}
+
+func comparisonError(ex filter.AttributeExpression, typ string) error {
+	return fmt.Errorf("%s: %s on %s attribute %s", unsupportedComparisonMessage, ex.Operator, typ, ex.Path)
+}
@@ -0,0 +1,219 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const evaluateUser = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"],
+	"id": "2819c223-7f76-453a-919d-413861904646",
+	"externalId": "701984",
+	"userName": "bjensen@example.com",
+	"name": {"familyName": "Jensen", "givenName": "Barbara"},
+	"title": "Tour Guide",
+	"userType": "Employee",
+	"active": true,
+	"emails": [
+		{"value": "bjensen@example.com", "type": "work", "primary": true},
+		{"value": "babs@jensen.org", "type": "home"}
+	],
+	"meta": {
+		"resourceType": "User",
+		"created": "2010-01-23T04:56:22Z",
+		"lastModified": "2011-05-13T04:42:34Z",
+		"version": "W/\"3694e05e9dff591\""
+	},
+	"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {
+		"employeeNumber": "701984",
+		"costCenter": "4130",
+		"manager": {"value": "26118915-6090-4610-87e4-49d8ca9f808d"}
+	}
+}`
+
+var evaluateSchemas = []Schema{
+	{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "userName", Type: String},
+			{Name: "title", Type: String, CaseExact: true},
+			{Name: "active", Type: Boolean},
+			{Name: "name", Type: Complex, SubAttributes: []Attribute{
+				{Name: "familyName", Type: String},
+				{Name: "givenName", Type: String},
+			}},
+			{Name: "emails", Type: Complex, Multivalued: true, SubAttributes: []Attribute{
+				{Name: "value", Type: String},
+				{Name: "type", Type: String},
+				{Name: "primary", Type: Boolean},
+			}},
+		},
+	},
+	{
+		CommonAttributes: CommonAttributes{ID: EnterpriseUserURN},
+		Attributes: []Attribute{
+			{Name: "employeeNumber", Type: String},
+			{Name: "costCenter", Type: String},
+			{Name: "manager", Type: Complex, SubAttributes: []Attribute{
+				{Name: "value", Type: String},
+			}},
+		},
+	},
+}
+
+func TestMatch(t *testing.T) {
+	user := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &user))
+
+	tests := []struct {
+		name   string
+		filter string
+		exp    bool
+	}{
+		{"Equal", `userName eq "bjensen@example.com"`, true},
+		{"Case-insensitive value", `userName eq "BJensen@Example.com"`, true},
+		{"Case-exact value", `title eq "tour guide"`, false},
+		{"Case-exact match", `title eq "Tour Guide"`, true},
+		{"Case-insensitive name", `USERNAME eq "bjensen@example.com"`, true},
+		{"Case-exact id", `id eq "2819C223-7F76-453A-919D-413861904646"`, false},
+		{"Not equal", `userType ne "Intern"`, true},
+		{"Not equal unassigned", `nickName ne "Babs"`, true},
+		{"Sub-attribute", `name.familyName co "ens"`, true},
+		{"Starts with", `userName sw "bjen"`, true},
+		{"Ends with", `userName ew ".org"`, false},
+		{"Present", `title pr`, true},
+		{"Not present", `nickName pr`, false},
+		{"Equal null", `nickName eq null`, true},
+		{"Boolean", `active eq true`, true},
+		{"DateTime", `meta.lastModified gt "2011-05-13T00:42:34-04:00"`, false},
+		{"DateTime greater or equal", `meta.lastModified ge "2011-05-13T00:42:34-04:00"`, true},
+		{"DateTime before", `meta.created lt "2011-01-01T00:00:00Z"`, true},
+		{"Multi-valued", `emails.value ew "jensen.org"`, true},
+		{"Multi-valued complex", `emails co "example.com"`, true},
+		{"Multi-valued type", `emails.type eq "other"`, false},
+		{"Value path", `emails[type eq "work" and value co "@example.com"]`, true},
+		{"Value path mismatch", `emails[type eq "home" and value co "@example.com"]`, false},
+		{"Value path boolean", `emails[primary eq true]`, true},
+		{"Extension", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber eq "701984"`, true},
+		{"Extension sub-attribute", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value sw "2611"`, true},
+		{"Extension attribute without URN", `employeeNumber eq "701984"`, false},
+		{"Unknown extension", `urn:example:params:scim:schemas:extension:User:employeeNumber pr`, false},
+		{"Fully qualified core attribute", `urn:ietf:params:scim:schemas:core:2.0:User:userName sw "b"`, true},
+		{"And", `title pr and userType eq "Employee"`, true},
+		{"Or", `title eq "x" or userType eq "Employee"`, true},
+		{"Not", `not (userType eq "Employee")`, false},
+		{"Grouping", `userType eq "Employee" and (emails co "example.org" or emails.value co "jensen.org")`, true},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := Match(filter.MustParse(test.filter), &user, evaluateSchemas...)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, act)
+		})
+	}
+}
+
+func TestMatchWithoutSchemas(t *testing.T) {
+	user := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &user))
+
+	// Without schemas every string is case-insensitive and dates are
+	// recognized by their format.
+	for _, f := range []string{
+		`title eq "tour guide"`,
+		`meta.lastModified gt "2011-01-01T00:00:00Z"`,
+		`emails co "EXAMPLE.COM"`,
+		`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:costCenter eq "4130"`,
+	} {
+		act, err := Match(filter.MustParse(f), &user)
+		require.NoError(t, err, f)
+		assert.True(t, act, f)
+	}
+}
+
+func TestMatchZeroTimeValues(t *testing.T) {
+	user := User{Title: "0001-01-01T00:00:00Z"}
+	for f, exp := range map[string]bool{
+		`title eq "0001-01-01T00:00:00Z"`: true,
+		`title pr`:                        true,
+		`meta.created pr`:                 false,
+		`meta.lastModified pr`:            false,
+	} {
+		act, err := Match(filter.MustParse(f), &user)
+		require.NoError(t, err, f)
+		assert.Equal(t, exp, act, f)
+	}
+}
+
+func TestCompareLargeIntegers(t *testing.T) {
+	tests := []struct {
+		op   filter.CompareOperator
+		a, b json.Number
+		exp  bool
+	}{
+		{filter.Gt, "9007199254740993", "9007199254740992", true},
+		{filter.Eq, "9007199254740993", "9007199254740992", false},
+		{filter.Lt, "-9223372036854775808", "-9223372036854775807", true},
+		{filter.Ge, "1.5", "1", true},
+		{filter.Lt, "9007199254740993", "9.1e15", true},
+	}
+
+	for _, test := range tests {
+		act, err := compareNumbers(filter.AttributeExpression{Operator: test.op}, test.a, test.b)
+		require.NoError(t, err)
+		assert.Equal(t, test.exp, act, "%s %s %s", test.a, test.op, test.b)
+	}
+}
+
+func TestMatchGroup(t *testing.T) {
+	group := Group{
+		DisplayName: "Tour Guides",
+		Members: []MemberRef{
+			{Value: "2819c223-7f76-453a-919d-413861904646", Multivalued: Multivalued{Type: "User"}},
+			{Value: "902c246b-6245-4190-8e05-00816be7344a", Multivalued: Multivalued{Type: "User"}},
+		},
+	}
+	act, err := Match(filter.MustParse(`displayName eq "tour guides" and members[value eq "902c246b-6245-4190-8e05-00816be7344a"]`), &group)
+	require.NoError(t, err)
+	assert.True(t, act)
+
+	// Unassigned dates are ignored.
+	act, err = Match(filter.MustParse(`meta.created pr`), &group)
+	require.NoError(t, err)
+	assert.False(t, act)
+}
+
+func TestMatchErrors(t *testing.T) {
+	user := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &user))
+
+	for _, f := range []string{
+		`active gt false`,
+		`active co true`,
+		`userName gt null`,
+		`name gt "x"`,
+	} {
+		_, err := Match(filter.MustParse(f), &user, evaluateSchemas...)
+		assert.Error(t, err, f)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	users := []Resource{
+		&User{UserName: "bjensen", Title: "Tour Guide"},
+		&User{UserName: "jsmith"},
+		&User{UserName: "mpepperidge", Title: "Ticket Agent"},
+	}
+	act, err := Select(filter.MustParse(`title pr`), users, evaluateSchemas...)
+	require.NoError(t, err)
+	assert.Equal(t, []Resource{users[0], users[2]}, act)
+
+	_, err = Select(filter.MustParse(`active gt true`), users, evaluateSchemas...)
+	assert.Error(t, err)
+}
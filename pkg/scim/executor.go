@@ -0,0 +1,211 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// OperationType identifies the Client method used to perform an
+// Operation.
+type OperationType string
+
+const (
+	CreateOperation   OperationType = "Create"
+	RetrieveOperation OperationType = "Retrieve"
+	ReplaceOperation  OperationType = "Replace"
+	UpdateOperation   OperationType = "Update"
+	UpsertOperation   OperationType = "Upsert"
+)
+
+const (
+	unknownOperationMessage = "unknown operation type"
+	noResourceMessage       = "operation requires a Resource"
+)
+
+// Operation describes a single SCIM request to be performed by Execute.
+type Operation struct {
+	Type     OperationType //Type selects the Client method used to perform the operation.
+	Resource Resource      //Resource is the resource that is sent to (and/or populated by) the SCIM server.
+	ID       string        //ID is the id of the resource for Retrieve and Update operations.
+	Mutate   Mutator       //Mutate is the function used to change the resource for Update operations.
+	Key      string        //Key optionally identifies the targeted resource in place of its id, externalId and Go pointer (see Execute).
+}
+
+// OperationResult is the outcome of an Operation performed by Execute.
+type OperationResult struct {
+	Operation Operation
+	Action    UpsertAction //Action is the action taken by an Upsert operation.
+	Err       error
+}
+
+// identities returns the keys that identify the resource targeted by the
+// operation at index i - the caller's Key or else the resource's Go
+// pointer, id and externalId.  Operations without a resource fail
+// without a request so they're given a key of their own.
+func (op Operation) identities(i int) []string {
+	switch {
+	case op.Key != "":
+		return []string{"key/" + op.Key}
+	case op.Resource == nil:
+		return []string{fmt.Sprintf("operation/%d", i)}
+	}
+	rt := op.Resource.ResourceType().Name
+	keys := []string{fmt.Sprintf("%s/%p", rt, op.Resource)}
+	for _, id := range []string{op.ID, op.Resource.getID()} {
+		if id != "" {
+			keys = append(keys, rt+"/id/"+id)
+		}
+	}
+	if externalID := op.Resource.getExternalID(); externalID != "" {
+		keys = append(keys, rt+"/externalId/"+externalID)
+	}
+	return keys
+}
+
+// operationQueues groups the indexes of the operations that share any identity
+// (so that an operation that creates a resource by externalId and one
+// that replaces it by id are performed in order).  Each queue's indexes
+// are in the order the operations were provided.
+func operationQueues(ops []Operation) [][]int {
+	parent := make([]int, len(ops))
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	owner := map[string]int{}
+	for i, op := range ops {
+		parent[i] = i
+		for _, key := range op.identities(i) {
+			j, ok := owner[key]
+			if !ok {
+				owner[key] = i
+				continue
+			}
+			if ri, rj := find(i), find(j); ri != rj {
+				if ri < rj {
+					ri, rj = rj, ri
+				}
+				parent[ri] = rj
+			}
+		}
+	}
+
+	result := [][]int{}
+	index := map[int]int{}
+	for i := range ops {
+		root := find(i)
+		q, ok := index[root]
+		if !ok {
+			q = len(result)
+			index[root] = q
+			result = append(result, nil)
+		}
+		result[q] = append(result[q], i)
+	}
+	return result
+}
+
+type executeCfg struct {
+	workers int
+}
+
+// ExecuteOpt changes the behavior of Execute.
+type ExecuteOpt func(*executeCfg)
+
+// Workers sets the number of operations Execute performs in parallel,
+// overriding the client's MaxConcurrency.
+func Workers(workers int) ExecuteOpt {
+	return func(cfg *executeCfg) {
+		cfg.workers = workers
+	}
+}
+
+// Execute performs the provided operations using a bounded number of
+// parallel workers (the client's MaxConcurrency by default).  Operations
+// that target the same resource (sharing an id, externalId, Go pointer or
+// Key) are performed sequentially in the order they were provided while
+// operations on different resources may be performed in any order.  If
+// the context is cancelled, operations that haven't started are not
+// performed and their results contain the context's error.  The
+// returned results are in the same order as the provided operations.
+func (c Client) Execute(ctx context.Context, ops []Operation, opts ...ExecuteOpt) []OperationResult {
+	cfg := executeCfg{
+		workers: c.cfg.MaxConcurrency,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	results := make([]OperationResult, len(ops))
+	for i, op := range ops {
+		results[i].Operation = op
+	}
+	queues := operationQueues(ops)
+
+	work := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency(cfg.workers); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for queue := range work {
+				for _, i := range queue {
+					if err := ctx.Err(); err != nil {
+						results[i].Err = err
+						continue
+					}
+					results[i].Action, results[i].Err = c.perform(ctx, ops[i])
+				}
+			}
+		}()
+	}
+
+	for q, queue := range queues {
+		select {
+		case work <- queue:
+		case <-ctx.Done():
+			for _, queue := range queues[q:] {
+				for _, i := range queue {
+					results[i].Err = ctx.Err()
+				}
+			}
+			close(work)
+			wg.Wait()
+			return results
+		}
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+// perform calls the Client method for the operation's type.  Incomplete
+// operations are reported as the operation's error rather than causing
+// the worker to panic.
+func (c Client) perform(ctx context.Context, op Operation) (UpsertAction, error) {
+	switch {
+	case op.Resource == nil:
+		return "", fmt.Errorf("%s: %s", noResourceMessage, op.Type)
+	case op.Type == UpdateOperation && op.Mutate == nil:
+		return "", errors.New(noMutatorMessage)
+	}
+	switch op.Type {
+	case CreateOperation:
+		return "", c.CreateResource(ctx, op.Resource)
+	case RetrieveOperation:
+		return "", c.RetrieveResource(ctx, op.Resource, op.ID)
+	case ReplaceOperation:
+		return "", c.ReplaceResource(ctx, op.Resource)
+	case UpdateOperation:
+		return "", c.Update(ctx, op.Resource, op.ID, op.Mutate)
+	case UpsertOperation:
+		return c.Upsert(ctx, op.Resource)
+	default:
+		return "", fmt.Errorf("%s: %s", unknownOperationMessage, op.Type)
+	}
+}
@@ -0,0 +1,123 @@
+package scim
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	order := map[string][]string{}
+	errs := []error{}
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			active++
+			if active > peak {
+				peak = active
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+			body, _ := ioutil.ReadAll(req.Body)
+			user := User{}
+			err := user.UnmarshalJSON(body)
+
+			mu.Lock()
+			active--
+			if err != nil {
+				errs = append(errs, err)
+			}
+			id := path.Base(req.URL.Path)
+			order[id] = append(order[id], user.DisplayName)
+			mu.Unlock()
+			return jsonResponse(200, string(body)), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+
+	ops := []Operation{}
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		for _, dn := range []string{"1", "2", "3"} {
+			ops = append(ops, Operation{
+				Type: ReplaceOperation,
+				Resource: &User{
+					CommonAttributes: CommonAttributes{ID: id},
+					DisplayName:      dn,
+				},
+			})
+		}
+	}
+	ops = append(ops,
+		Operation{Type: "Bogus", Resource: &User{}},
+		Operation{Type: UpdateOperation, Resource: &User{}, ID: "g"},
+		Operation{Type: CreateOperation},
+	)
+
+	results := c.Execute(context.Background(), ops, Workers(2))
+	require.Len(t, results, len(ops))
+	for i, result := range results[:len(ops)-3] {
+		assert.NoError(t, result.Err)
+		assert.Equal(t, ops[i], result.Operation)
+	}
+	assert.EqualError(t, results[len(ops)-3].Err, unknownOperationMessage+": Bogus")
+	assert.EqualError(t, results[len(ops)-2].Err, noMutatorMessage)
+	assert.EqualError(t, results[len(ops)-1].Err, noResourceMessage+": Create")
+	assert.Empty(t, errs)
+
+	assert.LessOrEqual(t, peak, 2)
+	for id, dns := range order {
+		assert.Equal(t, []string{"1", "2", "3"}, dns, id)
+	}
+}
+
+func TestExecuteCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			cancel()
+			return jsonResponse(200, `{"id":"a","userName":"bjensen"}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+
+	ops := []Operation{}
+	for _, id := range strings.Split("abcdefghij", "") {
+		ops = append(ops, Operation{Type: RetrieveOperation, Resource: &User{}, ID: id})
+	}
+
+	results := c.Execute(ctx, ops, Workers(1))
+	require.Len(t, results, len(ops))
+	assert.NoError(t, results[0].Err)
+	for _, result := range results[1:] {
+		assert.Equal(t, context.Canceled, result.Err)
+	}
+}
+
+func TestOperationQueues(t *testing.T) {
+	created := &User{CommonAttributes: CommonAttributes{ExternalID: "701984"}}
+	ops := []Operation{
+		{Type: CreateOperation, Resource: created},
+		{Type: ReplaceOperation, Resource: &User{CommonAttributes: CommonAttributes{ID: "2819c223", ExternalID: "701984"}}},
+		{Type: RetrieveOperation, Resource: &User{}, ID: "2819c223"},
+		{Type: RetrieveOperation, Resource: &Group{}, ID: "2819c223"},
+		{Type: CreateOperation, Resource: &User{}, Key: "bjensen"},
+		{Type: ReplaceOperation, Resource: &User{CommonAttributes: CommonAttributes{ID: "902c246b"}}, Key: "bjensen"},
+		{Type: RetrieveOperation, ID: "2819c223"},
+		{Type: RetrieveOperation, ID: "2819c223"},
+		{Type: UpdateOperation, Resource: created, ID: "2819c223"},
+	}
+	assert.Equal(t, [][]int{{0, 1, 2, 8}, {3}, {4, 5}, {6}, {7}}, operationQueues(ops))
+}
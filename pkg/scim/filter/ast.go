@@ -0,0 +1,174 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Expression is a node of a parsed filter.  The String method returns the
+// node's canonical filter text.
+type Expression interface {
+	String() string
+	precedence() int
+}
+
+// Operator precedence - higher values bind more tightly.
+const (
+	orPrecedence = iota
+	andPrecedence
+	unaryPrecedence
+)
+
+// CompareOperator is the operator of an AttributeExpression.
+type CompareOperator string
+
+const (
+	Eq CompareOperator = "eq" //Eq - equal
+	Ne CompareOperator = "ne" //Ne - not equal
+	Co CompareOperator = "co" //Co - contains
+	Sw CompareOperator = "sw" //Sw - starts with
+	Ew CompareOperator = "ew" //Ew - ends with
+	Gt CompareOperator = "gt" //Gt - greater than
+	Lt CompareOperator = "lt" //Lt - less than
+	Ge CompareOperator = "ge" //Ge - greater than or equal to
+	Le CompareOperator = "le" //Le - less than or equal to
+	Pr CompareOperator = "pr" //Pr - present (has value)
+)
+
+var compareOperators = map[string]CompareOperator{
+	"eq": Eq, "ne": Ne, "co": Co, "sw": Sw, "ew": Ew,
+	"gt": Gt, "lt": Lt, "ge": Ge, "le": Le, "pr": Pr,
+}
+
+// LogicalOperator is the operator of a LogicalExpression.
+type LogicalOperator string
+
+const (
+	And LogicalOperator = "and"
+	Or  LogicalOperator = "or"
+)
+
+// AttributePath identifies an attribute (and optionally a sub-attribute)
+// of a resource.  The URI is the schema URN prefix used to identify
+// extension attributes (or to fully qualify core attributes).
+// https://tools.ietf.org/html/rfc7644#section-3.10
+type AttributePath struct {
+	URI          string //URI is the optional schema URN - e.g. "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User".
+	Name         string //Name is the attribute's name - e.g. "emails".
+	SubAttribute string //SubAttribute is the optional sub-attribute's name - e.g. "value".
+}
+
+// String returns the attribute path in its filter notation.
+func (ap AttributePath) String() string {
+	s := ap.Name
+	if ap.SubAttribute != "" {
+		s += "." + ap.SubAttribute
+	}
+	if ap.URI != "" {
+		s = ap.URI + ":" + s
+	}
+	return s
+}
+
+// AttributeExpression compares an attribute's value to a literal value
+// (or, for the Pr operator, tests for the attribute's presence).  The
+// Value is a string, bool, json.Number or nil (null).
+type AttributeExpression struct {
+	Path     AttributePath
+	Operator CompareOperator
+	Value    interface{}
+}
+
+func (ae AttributeExpression) String() string {
+	if ae.Operator == Pr {
+		return ae.Path.String() + " pr"
+	}
+	return ae.Path.String() + " " + string(ae.Operator) + " " + FormatValue(ae.Value)
+}
+
+func (ae AttributeExpression) precedence() int {
+	return unaryPrecedence
+}
+
+// LogicalExpression combines two expressions with "and" or "or".
+type LogicalExpression struct {
+	Operator LogicalOperator
+	Left     Expression
+	Right    Expression
+}
+
+func (le LogicalExpression) String() string {
+	p := le.precedence()
+	// Logical operators are left-associative so a right operand of equal
+	// precedence must be grouped to preserve the tree's shape.
+	return group(le.Left, p, false) + " " + string(le.Operator) + " " + group(le.Right, p, true)
+}
+
+func (le LogicalExpression) precedence() int {
+	if le.Operator == And {
+		return andPrecedence
+	}
+	return orPrecedence
+}
+
+// NotExpression negates an expression.
+type NotExpression struct {
+	Expression Expression
+}
+
+func (ne NotExpression) String() string {
+	return "not (" + ne.Expression.String() + ")"
+}
+
+func (ne NotExpression) precedence() int {
+	return unaryPrecedence
+}
+
+// ValuePathExpression applies a filter to the values of a multi-valued
+// complex attribute - e.g. emails[type eq "work"].
+type ValuePathExpression struct {
+	Path   AttributePath
+	Filter Expression
+}
+
+func (vpe ValuePathExpression) String() string {
+	return vpe.Path.String() + "[" + vpe.Filter.String() + "]"
+}
+
+func (vpe ValuePathExpression) precedence() int {
+	return unaryPrecedence
+}
+
+func group(e Expression, p int, strict bool) string {
+	if e.precedence() < p || (strict && e.precedence() == p) {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// FormatValue returns the filter notation of a comparison value - strings
+// are quoted and escaped as JSON strings.
+func FormatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quote(val)
+	case json.Number:
+		return val.String()
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
+
+// quote returns the JSON string representation of s without the HTML
+// escaping performed by json.Marshal.
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
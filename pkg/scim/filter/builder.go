@@ -0,0 +1,242 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Filter is a filter expression constructed with the fluent builder
+// methods in this file.  Comparison values are always formatted as
+// literals so user-provided data can't alter the filter's structure.
+//
+//	f := filter.Attr("userName").Eq(userName).And(filter.Attr("active").Eq(true))
+//
+// Errors encountered while building (an invalid attribute path or an
+// unsupported value type) are retained and returned by Build.
+type Filter struct {
+	expr Expression
+	err  error
+}
+
+// Build returns the filter's canonical text or the first error encountered
+// while constructing it.
+func (f Filter) Build() (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if f.expr == nil {
+		return "", errors.New(emptyFilterMessage)
+	}
+	return f.expr.String(), nil
+}
+
+// Expression returns the filter's parsed representation or the first
+// error encountered while constructing it.
+func (f Filter) Expression() (Expression, error) {
+	if _, err := f.Build(); err != nil {
+		return nil, err
+	}
+	return f.expr, nil
+}
+
+// String returns the filter's canonical text or an empty string if the
+// filter is invalid.
+func (f Filter) String() string {
+	s, _ := f.Build()
+	return s
+}
+
+// And returns a filter matching resources that match this filter and all
+// of the others.
+func (f Filter) And(others ...Filter) Filter {
+	return f.combine(And, others)
+}
+
+// Or returns a filter matching resources that match this filter or any of
+// the others.
+func (f Filter) Or(others ...Filter) Filter {
+	return f.combine(Or, others)
+}
+
+func (f Filter) combine(op LogicalOperator, others []Filter) Filter {
+	for _, o := range others {
+		if f.err != nil {
+			break
+		}
+		switch {
+		case o.err != nil:
+			f.err = o.err
+		case o.expr == nil:
+			f.err = errors.New(emptyFilterMessage)
+		case f.expr == nil:
+			f.err = errors.New(emptyFilterMessage)
+		default:
+			f.expr = LogicalExpression{Operator: op, Left: f.expr, Right: o.expr}
+		}
+	}
+	return f
+}
+
+// Not returns a filter matching resources that don't match the provided
+// filter.
+func Not(f Filter) Filter {
+	if f.err != nil || f.expr == nil {
+		return f
+	}
+	return Filter{expr: NotExpression{Expression: f.expr}}
+}
+
+// Attribute is the attribute path of a filter under construction.
+type Attribute struct {
+	path AttributePath
+	err  error
+}
+
+// Attr starts a filter on the attribute identified by the provided path -
+// e.g. "userName", "name.familyName" or
+// "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber".
+func Attr(path string) Attribute {
+	ap, err := parseAttributePath(path)
+	return Attribute{path: ap, err: err}
+}
+
+// Eq matches resources where the attribute is equal to the value.
+func (a Attribute) Eq(v interface{}) Filter { return a.compare(Eq, v) }
+
+// Ne matches resources where the attribute is not equal to the value.
+func (a Attribute) Ne(v interface{}) Filter { return a.compare(Ne, v) }
+
+// Co matches resources where the attribute contains the value.
+func (a Attribute) Co(v interface{}) Filter { return a.compare(Co, v) }
+
+// Sw matches resources where the attribute starts with the value.
+func (a Attribute) Sw(v interface{}) Filter { return a.compare(Sw, v) }
+
+// Ew matches resources where the attribute ends with the value.
+func (a Attribute) Ew(v interface{}) Filter { return a.compare(Ew, v) }
+
+// Gt matches resources where the attribute is greater than the value.
+func (a Attribute) Gt(v interface{}) Filter { return a.compare(Gt, v) }
+
+// Ge matches resources where the attribute is greater than or equal to the
+// value.
+func (a Attribute) Ge(v interface{}) Filter { return a.compare(Ge, v) }
+
+// Lt matches resources where the attribute is less than the value.
+func (a Attribute) Lt(v interface{}) Filter { return a.compare(Lt, v) }
+
+// Le matches resources where the attribute is less than or equal to the
+// value.
+func (a Attribute) Le(v interface{}) Filter { return a.compare(Le, v) }
+
+// Pr matches resources where the attribute has a non-empty value.
+func (a Attribute) Pr() Filter {
+	if a.err != nil {
+		return Filter{err: a.err}
+	}
+	return Filter{expr: AttributeExpression{Path: a.path, Operator: Pr}}
+}
+
+// Where matches resources where at least one value of the multi-valued
+// complex attribute matches the provided filter - e.g.
+//
+//	filter.Attr("emails").Where(filter.Attr("type").Eq("work"))
+func (a Attribute) Where(f Filter) Filter {
+	switch {
+	case a.err != nil:
+		return Filter{err: a.err}
+	case f.err != nil:
+		return f
+	case f.expr == nil:
+		return Filter{err: errors.New(emptyFilterMessage)}
+	case a.path.SubAttribute != "":
+		return Filter{err: fmt.Errorf("value filters can't be applied to sub-attribute %s", a.path)}
+	}
+	return Filter{expr: ValuePathExpression{Path: a.path, Filter: f.expr}}
+}
+
+func (a Attribute) compare(op CompareOperator, v interface{}) Filter {
+	if a.err != nil {
+		return Filter{err: a.err}
+	}
+	lit, err := Literal(v)
+	if err != nil {
+		return Filter{err: err}
+	}
+	return Filter{expr: AttributeExpression{Path: a.path, Operator: op, Value: lit}}
+}
+
+//
+// Literals
+//
+
+const emptyFilterMessage = "filter is empty"
+
+// Literal converts the provided Go value to a filter comparison value.
+// Strings and booleans are used as-is, integers and floating point values
+// become json.Numbers and time.Time values are formatted as xsd:dateTime
+// strings in UTC (https://tools.ietf.org/html/rfc7643#section-2.3.5).
+// Pointers are dereferenced and nil becomes null.
+func Literal(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case string, bool, json.Number:
+		return val, nil
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano), nil
+	case *time.Time:
+		if val == nil {
+			return nil, nil
+		}
+		return Literal(*val)
+	case *string:
+		if val == nil {
+			return nil, nil
+		}
+		return *val, nil
+	case *bool:
+		if val == nil {
+			return nil, nil
+		}
+		return *val, nil
+	case int:
+		return json.Number(strconv.FormatInt(int64(val), 10)), nil
+	case int8:
+		return json.Number(strconv.FormatInt(int64(val), 10)), nil
+	case int16:
+		return json.Number(strconv.FormatInt(int64(val), 10)), nil
+	case int32:
+		return json.Number(strconv.FormatInt(int64(val), 10)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10)), nil
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(val), 10)), nil
+	case uint8:
+		return json.Number(strconv.FormatUint(uint64(val), 10)), nil
+	case uint16:
+		return json.Number(strconv.FormatUint(uint64(val), 10)), nil
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(val), 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(val, 10)), nil
+	case float32:
+		return formatFloat(float64(val), 32)
+	case float64:
+		return formatFloat(val, 64)
+	case fmt.Stringer:
+		return val.String(), nil
+	}
+	return nil, fmt.Errorf("unsupported filter value type %T", v)
+}
+
+func formatFloat(f float64, bits int) (interface{}, error) {
+	// json.Marshal rejects NaN and infinities which have no SCIM representation.
+	if _, err := json.Marshal(f); err != nil {
+		return nil, fmt.Errorf("unsupported filter value %v", f)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bits)), nil
+}
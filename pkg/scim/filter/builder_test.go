@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	lastModified := time.Date(2011, 5, 13, 0, 42, 34, 0, time.FixedZone("EDT", -4*60*60))
+	active := true
+
+	tests := []struct {
+		name string
+		inp  Filter
+		exp  string
+	}{
+		{"Equal", Attr("userName").Eq("bjensen"), `userName eq "bjensen"`},
+		{"Injection", Attr("userName").Eq(`x" or userName pr or userName eq "`), `userName eq "x\" or userName pr or userName eq \""`},
+		{"Control characters", Attr("displayName").Eq("tab\there\nnewline\\"), `displayName eq "tab\there\nnewline\\"`},
+		{"Boolean", Attr("active").Eq(true), `active eq true`},
+		{"Boolean pointer", Attr("active").Ne(&active), `active ne true`},
+		{"Integer", Attr("age").Ge(21), `age ge 21`},
+		{"Unsigned", Attr("count").Lt(uint8(7)), `count lt 7`},
+		{"Float", Attr("score").Gt(1.5), `score gt 1.5`},
+		{"DateTime", Attr("meta.lastModified").Gt(lastModified), `meta.lastModified gt "2011-05-13T04:42:34Z"`},
+		{"Null", Attr("title").Eq(nil), `title eq null`},
+		{"Present", Attr("title").Pr(), `title pr`},
+		{"Contains", Attr("emails").Co("example.com"), `emails co "example.com"`},
+		{"Starts with", Attr("userName").Sw("J"), `userName sw "J"`},
+		{"Ends with", Attr("userName").Ew("n"), `userName ew "n"`},
+		{"Less than or equal", Attr("age").Le(65), `age le 65`},
+		{"Extension", Attr("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber").Eq("701984"), `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber eq "701984"`},
+		{"And", Attr("title").Pr().And(Attr("userType").Eq("Employee")), `title pr and userType eq "Employee"`},
+		{"And many", Attr("a").Pr().And(Attr("b").Pr(), Attr("c").Pr()), `a pr and b pr and c pr`},
+		{"Or within and", Attr("userType").Eq("Employee").And(Attr("emails").Co("example.com").Or(Attr("emails.value").Co("example.org"))), `userType eq "Employee" and (emails co "example.com" or emails.value co "example.org")`},
+		{"Not", Not(Attr("title").Pr()), `not (title pr)`},
+		{"Where", Attr("emails").Where(Attr("type").Eq("work").And(Attr("value").Co("@example.com"))), `emails[type eq "work" and value co "@example.com"]`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := test.inp.Build()
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, act)
+			// Everything the builder produces must parse back to the same tree.
+			exp, err := test.inp.Expression()
+			require.NoError(t, err)
+			parsed, err := Parse(act)
+			require.NoError(t, err)
+			assert.Equal(t, exp.String(), parsed.String())
+		})
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		inp  Filter
+	}{
+		{"Invalid attribute", Attr(`userName eq "x" or userName`).Pr()},
+		{"Unsupported value", Attr("userName").Eq(struct{}{})},
+		{"Not a number", Attr("score").Eq(func() float64 { z := 0.0; return z / z }())},
+		{"Invalid operand", Attr("title").Pr().And(Attr("1").Pr())},
+		{"Empty operand", Attr("title").Pr().Or(Filter{})},
+		{"Empty", Filter{}},
+		{"Value filter on sub-attribute", Attr("name.givenName").Where(Attr("value").Pr())},
+		{"Negated invalid", Not(Attr("").Pr())},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.inp.Build()
+			assert.Error(t, err)
+			assert.Equal(t, "", test.inp.String())
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package filter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LDAPMapping maps SCIM attribute paths (e.g. "userName", "name.familyName"
+// or "emails.value") to LDAP attribute descriptions (e.g. "uid", "sn" or
+// "mail").  Both sides are matched case-insensitively.
+type LDAPMapping map[string]string
+
+// ldap returns the LDAP attribute that the SCIM attribute path is mapped
+// to.
+func (m LDAPMapping) ldap(path AttributePath) (string, bool) {
+	s := path.String()
+	for k, v := range m {
+		if strings.EqualFold(k, s) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// scim returns the SCIM attribute path that the LDAP attribute is mapped
+// from.  If several paths are mapped to the same LDAP attribute, the
+// first in lexical order is used.
+func (m LDAPMapping) scim(attr string) (AttributePath, bool) {
+	paths := []string{}
+	for k, v := range m {
+		if strings.EqualFold(v, attr) {
+			paths = append(paths, k)
+		}
+	}
+	if len(paths) == 0 {
+		return AttributePath{}, false
+	}
+	sort.Strings(paths)
+	ap, err := parseAttributePath(paths[0])
+	return ap, err == nil
+}
+
+// Untranslatable describes a construct that has no equivalent in the
+// target filter syntax.
+type Untranslatable struct {
+	Construct string //Construct is the filter text that couldn't be translated.
+	Reason    string //Reason describes why the construct couldn't be translated.
+}
+
+// TranslationError is returned when a filter contains constructs that
+// can't be translated.  Every untranslatable construct is reported.
+type TranslationError struct {
+	Filter         string
+	Untranslatable []Untranslatable
+}
+
+func (te TranslationError) Error() string {
+	msgs := make([]string, len(te.Untranslatable))
+	for i, u := range te.Untranslatable {
+		msgs[i] = u.Construct + ": " + u.Reason
+	}
+	return fmt.Sprintf("can't translate filter %q - %s", te.Filter, strings.Join(msgs, "; "))
+}
+
+//
+// SCIM to LDAP
+//
+
+// ToLDAP translates a SCIM filter into an RFC4515 LDAP filter using the
+// provided attribute mapping.  Strings are escaped as LDAP assertion
+// values, booleans become "TRUE" or "FALSE" and "gt"/"lt" comparisons are
+// expressed as the corresponding inclusive comparison excluding equality.
+// "co", "sw" and "ew" comparisons to an empty string become presence
+// filters.  Value filters (e.g. emails[type eq "work"]), comparisons to null other
+// than "eq" and "ne" and attributes missing from the mapping can't be
+// translated and are reported in a TranslationError.
+// https://tools.ietf.org/html/rfc4515
+func ToLDAP(expr Expression, mapping LDAPMapping) (string, error) {
+	t := toLDAP{mapping: mapping}
+	s := t.translate(expr)
+	if len(t.problems) > 0 {
+		return "", TranslationError{Filter: expr.String(), Untranslatable: t.problems}
+	}
+	return s, nil
+}
+
+type toLDAP struct {
+	mapping  LDAPMapping
+	problems []Untranslatable
+}
+
+func (t *toLDAP) report(e Expression, format string, a ...interface{}) string {
+	t.problems = append(t.problems, Untranslatable{e.String(), fmt.Sprintf(format, a...)})
+	return ""
+}
+
+func (t *toLDAP) translate(expr Expression) string {
+	switch e := expr.(type) {
+	case LogicalExpression:
+		op := "&"
+		if e.Operator == Or {
+			op = "|"
+		}
+		return "(" + op + t.operands(e.Operator, e) + ")"
+	case NotExpression:
+		return "(!" + t.translate(e.Expression) + ")"
+	case ValuePathExpression:
+		return t.report(e, "LDAP filters can't require that several assertions match the same value")
+	case AttributeExpression:
+		return t.attribute(e)
+	}
+	return t.report(expr, "unknown expression")
+}
+
+// operands flattens nested expressions with the same logical operator
+// into a single LDAP filter set - (&(a)(b)(c)) instead of (&(&(a)(b))(c)).
+func (t *toLDAP) operands(op LogicalOperator, expr Expression) string {
+	if le, ok := expr.(LogicalExpression); ok && le.Operator == op {
+		return t.operands(op, le.Left) + t.operands(op, le.Right)
+	}
+	return t.translate(expr)
+}
+
+func (t *toLDAP) attribute(e AttributeExpression) string {
+	attr, ok := t.mapping.ldap(e.Path)
+	if !ok {
+		return t.report(e, "attribute %s isn't mapped to an LDAP attribute", e.Path)
+	}
+
+	if e.Operator == Pr || e.Value == nil {
+		switch {
+		case e.Operator == Pr || e.Operator == Ne:
+			return "(" + attr + "=*)"
+		case e.Operator == Eq:
+			return "(!(" + attr + "=*))"
+		}
+		return t.report(e, "%s can't be compared to null", e.Operator)
+	}
+
+	var v string
+	switch val := e.Value.(type) {
+	case string:
+		v = escapeLDAP(val)
+	case bool:
+		v = "FALSE"
+		if val {
+			v = "TRUE"
+		}
+	case json.Number:
+		v = val.String()
+	default:
+		return t.report(e, "unsupported value %v", val)
+	}
+
+	// Every string contains, starts and ends with the empty string but
+	// (attr=**) isn't a valid LDAP filter.
+	if v == "" && (e.Operator == Co || e.Operator == Sw || e.Operator == Ew) {
+		return "(" + attr + "=*)"
+	}
+
+	switch e.Operator {
+	case Eq:
+		return "(" + attr + "=" + v + ")"
+	case Ne:
+		return "(!(" + attr + "=" + v + "))"
+	case Co:
+		return "(" + attr + "=*" + v + "*)"
+	case Sw:
+		return "(" + attr + "=" + v + "*)"
+	case Ew:
+		return "(" + attr + "=*" + v + ")"
+	case Ge:
+		return "(" + attr + ">=" + v + ")"
+	case Le:
+		return "(" + attr + "<=" + v + ")"
+	case Gt:
+		return "(&(" + attr + ">=" + v + ")(!(" + attr + "=" + v + ")))"
+	case Lt:
+		return "(&(" + attr + "<=" + v + ")(!(" + attr + "=" + v + ")))"
+	}
+	return t.report(e, "unknown operator %s", e.Operator)
+}
+
+// escapeLDAP escapes the characters that are special in an RFC4515
+// assertion value.
+func escapeLDAP(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+//
+// LDAP to SCIM
+//
+
+// FromLDAP translates an RFC4515 LDAP filter into a SCIM filter using the
+// provided attribute mapping.  Assertion values become strings except for
+// "TRUE" and "FALSE" which become booleans.  Approximate matches,
+// extensible matches, substring assertions with both an initial and a
+// final component (sw and ew would also match values where the two
+// overlap), substring assertions with more than one inner component and
+// attributes missing from the mapping can't be translated
+// and are reported in a TranslationError.  Malformed LDAP filters result
+// in a SyntaxError.
+func FromLDAP(filter string, mapping LDAPMapping) (Expression, error) {
+	p := &ldapParser{filter: filter, mapping: mapping}
+	p.skipSpace()
+	e, err := p.parseFilter()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.filter) {
+		return nil, p.errorf("unexpected %q after filter", p.filter[p.pos])
+	}
+	if len(p.problems) > 0 {
+		return nil, TranslationError{Filter: filter, Untranslatable: p.problems}
+	}
+	return e, nil
+}
+
+type ldapParser struct {
+	filter   string
+	pos      int
+	mapping  LDAPMapping
+	problems []Untranslatable
+}
+
+func (p *ldapParser) errorf(format string, a ...interface{}) error {
+	return SyntaxError{Filter: p.filter, Offset: p.pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (p *ldapParser) skipSpace() {
+	for p.pos < len(p.filter) && p.filter[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *ldapParser) expect(c byte) error {
+	if p.pos >= len(p.filter) {
+		return p.errorf("expected %q but found end of filter", c)
+	}
+	if p.filter[p.pos] != c {
+		return p.errorf("expected %q but found %q", c, p.filter[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// parseFilter parses: filter = LPAREN filtercomp RPAREN
+func (p *ldapParser) parseFilter() (Expression, error) {
+	start := p.pos
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.filter) {
+		return nil, p.errorf("unterminated filter")
+	}
+
+	var e Expression
+	var err error
+	switch p.filter[p.pos] {
+	case '&', '|':
+		e, err = p.parseSet()
+	case '!':
+		p.pos++
+		e, err = p.parseFilter()
+		if err == nil && e != nil {
+			e = NotExpression{Expression: e}
+		}
+	default:
+		e, err = p.parseItem(start)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// parseSet parses: and = AMPERSAND filterlist / or = VERTBAR filterlist
+func (p *ldapParser) parseSet() (Expression, error) {
+	op := And
+	if p.filter[p.pos] == '|' {
+		op = Or
+	}
+	p.pos++
+
+	var e Expression
+	for p.skipSpace(); p.pos < len(p.filter) && p.filter[p.pos] == '('; p.skipSpace() {
+		f, err := p.parseFilter()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case f == nil:
+		case e == nil:
+			e = f
+		default:
+			e = LogicalExpression{Operator: op, Left: e, Right: f}
+		}
+	}
+	if e == nil && len(p.problems) == 0 {
+		return nil, p.errorf("empty filter list")
+	}
+	return e, nil
+}
+
+// parseItem parses a simple, present, substring or extensible item.  A
+// nil expression (and no error) is returned for untranslatable items.
+func (p *ldapParser) parseItem(start int) (Expression, error) {
+	end := strings.IndexByte(p.filter[p.pos:], ')')
+	if end < 0 {
+		return nil, p.errorf("unterminated filter")
+	}
+	item := p.filter[p.pos : p.pos+end]
+
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, p.errorf("expected attribute and assertion value but found %q", item)
+	}
+	attr, value := item[:eq], item[eq+1:]
+	kind := "="
+	switch attr[len(attr)-1] {
+	case '>', '<', '~', ':':
+		kind = attr[len(attr)-1:] + "="
+		attr = attr[:len(attr)-1]
+	}
+	if strings.ContainsRune(attr, ':') || kind == ":=" {
+		p.pos += end
+		return p.untranslatable(start, "extensible matches aren't supported by SCIM filters")
+	}
+	if !isLDAPAttribute(attr) {
+		return nil, p.errorf("invalid attribute description %q", attr)
+	}
+	if kind == "~=" {
+		p.pos += end
+		return p.untranslatable(start, "approximate matches aren't supported by SCIM filters")
+	}
+
+	path, ok := p.mapping.scim(attr)
+	if !ok {
+		p.pos += end
+		return p.untranslatable(start, fmt.Sprintf("attribute %s isn't mapped to a SCIM attribute", attr))
+	}
+
+	if kind == "=" && value == "*" {
+		p.pos += end
+		return AttributeExpression{Path: path, Operator: Pr}, nil
+	}
+
+	parts := strings.Split(value, "*")
+	values := make([]string, len(parts))
+	for i, part := range parts {
+		v, err := unescapeLDAP(part)
+		if err != nil {
+			p.pos += strings.Index(item, part)
+			return nil, p.errorf("%v", err)
+		}
+		values[i] = v
+	}
+	p.pos += end
+
+	switch {
+	case len(values) == 1:
+		op := Eq
+		if kind == ">=" {
+			op = Ge
+		} else if kind == "<=" {
+			op = Le
+		}
+		return AttributeExpression{Path: path, Operator: op, Value: ldapValue(values[0])}, nil
+	case kind != "=":
+		return nil, p.errorf("substring assertions can only be used with =")
+	case len(values) == 2 && values[0] == "":
+		return AttributeExpression{Path: path, Operator: Ew, Value: values[1]}, nil
+	case len(values) == 2 && values[1] == "":
+		return AttributeExpression{Path: path, Operator: Sw, Value: values[0]}, nil
+	case len(values) == 2:
+		return p.untranslatable(start, "SCIM filters can't require initial and final substrings that don't overlap")
+	case len(values) == 3 && values[0] == "" && values[2] == "" && values[1] != "":
+		return AttributeExpression{Path: path, Operator: Co, Value: values[1]}, nil
+	}
+	return p.untranslatable(start, "SCIM filters can't require substrings to appear in order")
+}
+
+func (p *ldapParser) untranslatable(start int, reason string) (Expression, error) {
+	p.problems = append(p.problems, Untranslatable{p.filter[start : p.pos+1], reason})
+	return nil, nil
+}
+
+// ldapValue converts an LDAP assertion value to a SCIM comparison value.
+func ldapValue(s string) interface{} {
+	switch s {
+	case "TRUE":
+		return true
+	case "FALSE":
+		return false
+	}
+	return s
+}
+
+func isLDAPAttribute(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == ';' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// unescapeLDAP decodes the \XX escapes of an RFC4515 assertion value.
+func unescapeLDAP(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+3 > len(s) {
+			return "", fmt.Errorf("incomplete escape in %q", s)
+		}
+		decoded, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid escape \\%s in %q", s[i+1:i+3], s)
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ldapMapping = LDAPMapping{
+	"userName":          "uid",
+	"name.familyName":   "sn",
+	"name.givenName":    "givenName",
+	"emails.value":      "mail",
+	"emails":            "mail",
+	"active":            "psuActive",
+	"meta.lastModified": "modifyTimestamp",
+	"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber": "employeeNumber",
+}
+
+func TestToLDAP(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		exp    string
+	}{
+		{"Equal", `userName eq "bjensen"`, `(uid=bjensen)`},
+		{"Case-insensitive mapping", `USERNAME eq "bjensen"`, `(uid=bjensen)`},
+		{"Not equal", `userName ne "bjensen"`, `(!(uid=bjensen))`},
+		{"Contains", `name.familyName co "ens"`, `(sn=*ens*)`},
+		{"Starts with", `name.familyName sw "J"`, `(sn=J*)`},
+		{"Ends with", `name.familyName ew "sen"`, `(sn=*sen)`},
+		{"Contains empty", `name.familyName co ""`, `(sn=*)`},
+		{"Starts with empty", `name.familyName sw ""`, `(sn=*)`},
+		{"Ends with empty", `name.familyName ew ""`, `(sn=*)`},
+		{"Present", `emails pr`, `(mail=*)`},
+		{"Equal null", `emails eq null`, `(!(mail=*))`},
+		{"Not equal null", `emails ne null`, `(mail=*)`},
+		{"Greater or equal", `meta.lastModified ge "20110513044234Z"`, `(modifyTimestamp>=20110513044234Z)`},
+		{"Less or equal", `employeeNumber le 7`, ``},
+		{"Greater", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber gt 701984`, `(&(employeeNumber>=701984)(!(employeeNumber=701984)))`},
+		{"Less", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber lt 5`, `(&(employeeNumber<=5)(!(employeeNumber=5)))`},
+		{"Boolean", `active eq true`, `(psuActive=TRUE)`},
+		{"Escaping", `name.familyName eq "*(O'Malley)\\"`, `(sn=\2a\28O'Malley\29\5c)`},
+		{"And", `userName eq "a" and emails.value co "b" and active eq false`, `(&(uid=a)(mail=*b*)(psuActive=FALSE))`},
+		{"Or within and", `userName eq "a" and (emails co "b" or emails co "c")`, `(&(uid=a)(|(mail=*b*)(mail=*c*)))`},
+		{"Not", `not (userName sw "x" or userName sw "y")`, `(!(|(uid=x*)(uid=y*)))`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := ToLDAP(MustParse(test.filter), ldapMapping)
+			if test.exp == "" {
+				assert.IsType(t, TranslationError{}, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, act)
+		})
+	}
+}
+
+func TestToLDAPUntranslatable(t *testing.T) {
+	_, err := ToLDAP(MustParse(`userName eq "x" or emails[type eq "work"] or title pr or userName gt null`), ldapMapping)
+	require.IsType(t, TranslationError{}, err)
+	assert.Equal(t, []Untranslatable{
+		{`emails[type eq "work"]`, "LDAP filters can't require that several assertions match the same value"},
+		{`title pr`, "attribute title isn't mapped to an LDAP attribute"},
+		{`userName gt null`, "gt can't be compared to null"},
+	}, err.(TranslationError).Untranslatable)
+}
+
+func TestFromLDAP(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		exp    string
+	}{
+		{"Equal", `(uid=bjensen)`, `userName eq "bjensen"`},
+		{"Case-insensitive mapping", `(UID=bjensen)`, `userName eq "bjensen"`},
+		{"Present", `(mail=*)`, `emails pr`},
+		{"Greater or equal", `(modifyTimestamp>=20110513044234Z)`, `meta.lastModified ge "20110513044234Z"`},
+		{"Less or equal", `(employeeNumber<=5)`, `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber le "5"`},
+		{"Contains", `(sn=*ens*)`, `name.familyName co "ens"`},
+		{"Starts with", `(sn=J*)`, `name.familyName sw "J"`},
+		{"Ends with", `(sn=*sen)`, `name.familyName ew "sen"`},
+		{"Boolean", `(psuActive=TRUE)`, `active eq true`},
+		{"Escaping", `(sn=\2a\28O'Malley\29\5c)`, `name.familyName eq "*(O'Malley)\\"`},
+		{"And", `(&(uid=a)(mail=*b*)(psuActive=FALSE))`, `userName eq "a" and emails co "b" and active eq false`},
+		{"Nested", `(&(uid=a)(|(mail=*b*)(mail=*c*)))`, `userName eq "a" and (emails co "b" or emails co "c")`},
+		{"Not", `(!(uid=x*))`, `not (userName sw "x")`},
+		{"Whitespace between filters", `(& (uid=a) (sn=b))`, `userName eq "a" and name.familyName eq "b"`},
+		{"Round-trip of gt", `(&(employeeNumber>=7)(!(employeeNumber=7)))`, `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber ge "7" and not (urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber eq "7")`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := FromLDAP(test.filter, ldapMapping)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, act.String())
+		})
+	}
+}
+
+func TestFromLDAPUntranslatable(t *testing.T) {
+	_, err := FromLDAP(`(|(uid=a)(cn~=Babs)(uid:caseExactMatch:=bjensen)(sn=J*e*n)(sn=ab*ba)(ou=Tours))`, ldapMapping)
+	require.IsType(t, TranslationError{}, err)
+	assert.Equal(t, []Untranslatable{
+		{`(cn~=Babs)`, "approximate matches aren't supported by SCIM filters"},
+		{`(uid:caseExactMatch:=bjensen)`, "extensible matches aren't supported by SCIM filters"},
+		{`(sn=J*e*n)`, "SCIM filters can't require substrings to appear in order"},
+		{`(sn=ab*ba)`, "SCIM filters can't require initial and final substrings that don't overlap"},
+		{`(ou=Tours)`, "attribute ou isn't mapped to a SCIM attribute"},
+	}, err.(TranslationError).Untranslatable)
+}
+
+func TestFromLDAPSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		offset int
+	}{
+		{"Empty", ``, 0},
+		{"Missing parens", `uid=bjensen`, 0},
+		{"Unterminated", `(uid=bjensen`, 1},
+		{"Missing value", `(uid)`, 1},
+		{"Invalid escape", `(uid=a\zz)`, 5},
+		{"Trailing", `(uid=a)x`, 7},
+		{"Empty list", `(&)`, 2},
+		{"Invalid attribute", `(u d=a)`, 1},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromLDAP(test.filter, ldapMapping)
+			require.IsType(t, SyntaxError{}, err)
+			assert.Equal(t, test.offset, err.(SyntaxError).Offset, err.Error())
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SyntaxError describes a malformed filter.  Offset is the zero-based
+// byte offset within the filter at which the error was detected.
+type SyntaxError struct {
+	Filter string
+	Offset int
+	Msg    string
+}
+
+func (se SyntaxError) Error() string {
+	return fmt.Sprintf("filter syntax error at offset %d: %s", se.Offset, se.Msg)
+}
+
+type tokenType int
+
+const (
+	eofToken tokenType = iota
+	wordToken
+	stringToken
+	numberToken
+	lparenToken
+	rparenToken
+	lbracketToken
+	rbracketToken
+)
+
+func (tt tokenType) String() string {
+	switch tt {
+	case eofToken:
+		return "end of filter"
+	case stringToken:
+		return "string"
+	case numberToken:
+		return "number"
+	case lparenToken:
+		return "\"(\""
+	case rparenToken:
+		return "\")\""
+	case lbracketToken:
+		return "\"[\""
+	case rbracketToken:
+		return "\"]\""
+	default:
+		return "word"
+	}
+}
+
+type token struct {
+	typ    tokenType
+	text   string
+	offset int
+}
+
+func (t token) String() string {
+	if t.typ == eofToken {
+		return t.typ.String()
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits a filter into tokens.
+func lex(filter string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(filter); {
+		c := filter[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{lparenToken, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{rparenToken, ")", i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{lbracketToken, "[", i})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{rbracketToken, "]", i})
+			i++
+		case c == '"':
+			end, err := scanString(filter, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{stringToken, filter[i:end], i})
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := scanNumber(filter, i)
+			if end == i {
+				return nil, SyntaxError{filter, i, fmt.Sprintf("unexpected character %q", c)}
+			}
+			tokens = append(tokens, token{numberToken, filter[i:end], i})
+			i = end
+		case isWordChar(c):
+			end := i
+			for end < len(filter) && isWordChar(filter[end]) {
+				end++
+			}
+			tokens = append(tokens, token{wordToken, filter[i:end], i})
+			i = end
+		default:
+			return nil, SyntaxError{filter, i, fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return append(tokens, token{eofToken, "", len(filter)}), nil
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.' || c == ':' || c == '$'
+}
+
+// scanString returns the offset following the JSON string that starts at
+// the provided offset.
+func scanString(filter string, start int) (int, error) {
+	for i := start + 1; i < len(filter); i++ {
+		switch filter[i] {
+		case '\\':
+			i++
+		case '"':
+			var s string
+			if err := json.Unmarshal([]byte(filter[start:i+1]), &s); err != nil {
+				return 0, SyntaxError{filter, start, "invalid string: " + strings.TrimPrefix(err.Error(), "json: ")}
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, SyntaxError{filter, start, "unterminated string"}
+}
+
+// scanNumber returns the offset following the JSON number that starts at
+// the provided offset (or the offset itself if there is no number or it
+// isn't a valid JSON number - e.g. 01).
+func scanNumber(filter string, start int) int {
+	i := start
+	digits := func() int {
+		n := 0
+		for i < len(filter) && filter[i] >= '0' && filter[i] <= '9' {
+			i++
+			n++
+		}
+		return n
+	}
+	if i < len(filter) && filter[i] == '-' {
+		i++
+	}
+	// The integer part is either a single zero or doesn't start with one.
+	switch {
+	case i < len(filter) && filter[i] == '0':
+		i++
+		if i < len(filter) && filter[i] >= '0' && filter[i] <= '9' {
+			return start
+		}
+	case digits() == 0:
+		return start
+	}
+	if i < len(filter) && filter[i] == '.' {
+		i++
+		if digits() == 0 {
+			return start
+		}
+	}
+	if i < len(filter) && (filter[i] == 'e' || filter[i] == 'E') {
+		i++
+		if i < len(filter) && (filter[i] == '+' || filter[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return start
+		}
+	}
+	return i
+}
@@ -0,0 +1,273 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parse parses the provided filter into an Expression.
+func Parse(filter string) (Expression, error) {
+	tokens, err := lex(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{filter: filter, tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.typ != eofToken {
+		return nil, p.errorf(t, "unexpected %s after expression", t)
+	}
+	return e, nil
+}
+
+// MustParse is like Parse but panics if the filter is malformed.  It is
+// intended for filters that are constants.
+func MustParse(filter string) Expression {
+	e, err := Parse(filter)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+type parser struct {
+	filter  string
+	tokens  []token
+	pos     int
+	inValue bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != eofToken {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, a ...interface{}) error {
+	return SyntaxError{
+		Filter: p.filter,
+		Offset: t.offset,
+		Msg:    fmt.Sprintf(format, a...),
+	}
+}
+
+func (p *parser) expect(typ tokenType) (token, error) {
+	t := p.next()
+	if t.typ != typ {
+		return t, p.errorf(t, "expected %s but found %s", typ, t)
+	}
+	return t, nil
+}
+
+func (p *parser) isKeyword(t token, keyword string) bool {
+	return t.typ == wordToken && strings.EqualFold(t.text, keyword)
+}
+
+// parseOr parses: andExp *(SP "or" SP andExp)
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), string(Or)) {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpression{Operator: Or, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unaryExp *(SP "and" SP unaryExp)
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), string(And)) {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpression{Operator: And, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: "not" "(" FILTER ")" / "(" FILTER ")" / attrExp / valuePath
+func (p *parser) parseUnary() (Expression, error) {
+	t := p.peek()
+	switch {
+	case p.isKeyword(t, "not"):
+		p.next()
+		if lp := p.peek(); lp.typ != lparenToken {
+			return nil, p.errorf(lp, "expected ( after \"not\" but found %s", lp)
+		}
+		e, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpression{Expression: e}, nil
+	case t.typ == lparenToken:
+		return p.parseGroup()
+	case p.isKeyword(t, string(And)) || p.isKeyword(t, string(Or)):
+		return nil, p.errorf(t, "expected attribute path, \"not\" or \"(\" but found %s", t)
+	case t.typ == wordToken:
+		return p.parseAttribute()
+	default:
+		return nil, p.errorf(t, "expected attribute path, \"not\" or \"(\" but found %s", t)
+	}
+}
+
+// parseGroup parses: "(" FILTER ")"
+func (p *parser) parseGroup() (Expression, error) {
+	if _, err := p.expect(lparenToken); err != nil {
+		return nil, err
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(rparenToken); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// parseAttribute parses: attrPath SP "pr" / attrPath SP compareOp SP compValue / attrPath "[" valFilter "]"
+func (p *parser) parseAttribute() (Expression, error) {
+	t := p.next()
+	path, err := parseAttributePath(t.text)
+	if err != nil {
+		return nil, p.errorf(t, "%v", err)
+	}
+
+	if p.peek().typ == lbracketToken {
+		vp, err := p.parseValuePath(path, p.next())
+		if err != nil {
+			return nil, err
+		}
+		return vp, nil
+	}
+
+	opt := p.next()
+	op, ok := compareOperators[strings.ToLower(opt.text)]
+	if opt.typ != wordToken || !ok {
+		return nil, p.errorf(opt, "expected comparison operator but found %s", opt)
+	}
+	if op == Pr {
+		return AttributeExpression{Path: path, Operator: op}, nil
+	}
+
+	vt := p.next()
+	value, err := p.parseValue(vt)
+	if err != nil {
+		return nil, err
+	}
+	return AttributeExpression{Path: path, Operator: op, Value: value}, nil
+}
+
+// parseValuePath parses the: "[" valFilter "]" that follows an attribute
+// path (the "[" has already been consumed).
+func (p *parser) parseValuePath(path AttributePath, bracket token) (ValuePathExpression, error) {
+	if p.inValue {
+		return ValuePathExpression{}, p.errorf(bracket, "value filters can't be nested")
+	}
+	if path.SubAttribute != "" {
+		return ValuePathExpression{}, p.errorf(bracket, "value filters can't be applied to sub-attribute %s", path)
+	}
+	p.inValue = true
+	e, err := p.parseOr()
+	p.inValue = false
+	if err != nil {
+		return ValuePathExpression{}, err
+	}
+	if _, err := p.expect(rbracketToken); err != nil {
+		return ValuePathExpression{}, err
+	}
+	return ValuePathExpression{Path: path, Filter: e}, nil
+}
+
+// parseValue parses: false / null / true / number / string
+func (p *parser) parseValue(t token) (interface{}, error) {
+	switch t.typ {
+	case stringToken:
+		var s string
+		_ = json.Unmarshal([]byte(t.text), &s)
+		return s, nil
+	case numberToken:
+		return json.Number(t.text), nil
+	case wordToken:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	}
+	return nil, p.errorf(t, "expected string, number, true, false or null but found %s", t)
+}
+
+// parseAttributePath parses: [URI ":"] ATTRNAME *1subAttr
+func parseAttributePath(s string) (AttributePath, error) {
+	ap := AttributePath{}
+	rest := s
+	if idx := strings.LastIndex(s, ":"); idx >= 0 {
+		ap.URI = s[:idx]
+		rest = s[idx+1:]
+		if !strings.HasPrefix(strings.ToLower(ap.URI), "urn:") {
+			return ap, fmt.Errorf("invalid schema URI %q", ap.URI)
+		}
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 2 {
+		return ap, fmt.Errorf("invalid attribute path %q - too many sub-attributes", s)
+	}
+	for _, part := range parts {
+		if !isAttributeName(part) {
+			return ap, fmt.Errorf("invalid attribute name %q in %q", part, s)
+		}
+	}
+	ap.Name = parts[0]
+	if len(parts) == 2 {
+		ap.SubAttribute = parts[1]
+	}
+	return ap, nil
+}
+
+// isAttributeName reports whether the provided string is a valid
+// ATTRNAME (ALPHA *(nameChar)) or the special "$ref" attribute name.
+func isAttributeName(s string) bool {
+	if s == "$ref" {
+		return true
+	}
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		alpha := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if i == 0 && !alpha {
+			return false
+		}
+		if !alpha && !(c >= '0' && c <= '9') && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
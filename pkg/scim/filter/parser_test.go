@@ -0,0 +1,133 @@
+package filter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		inp  string
+		exp  string
+	}{
+		// Examples from https://tools.ietf.org/html/rfc7644#section-3.4.2.2
+		{"Equal", `userName Eq "john"`, `userName eq "john"`},
+		{"Fully qualified", `urn:ietf:params:scim:schemas:core:2.0:User:name.familyName co "O'Malley"`, `urn:ietf:params:scim:schemas:core:2.0:User:name.familyName co "O'Malley"`},
+		{"Starts with", `userName sw "J"`, `userName sw "J"`},
+		{"Present", `title pr`, `title pr`},
+		{"Date", `meta.lastModified gt "2011-05-13T04:42:34Z"`, `meta.lastModified gt "2011-05-13T04:42:34Z"`},
+		{"And", `title pr and userType eq "Employee"`, `title pr and userType eq "Employee"`},
+		{"Or", `title pr or userType eq "Intern"`, `title pr or userType eq "Intern"`},
+		{"Schemas", `schemas eq "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`, `schemas eq "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`},
+		{"Grouping", `userType eq "Employee" and (emails co "example.com" or emails.value co "example.org")`, `userType eq "Employee" and (emails co "example.com" or emails.value co "example.org")`},
+		{"Not", `userType ne "Employee" and not (emails co "example.com" or emails.value co "example.org")`, `userType ne "Employee" and not (emails co "example.com" or emails.value co "example.org")`},
+		{"Value path", `userType eq "Employee" and emails[type eq "work" and value co "@example.com"]`, `userType eq "Employee" and emails[type eq "work" and value co "@example.com"]`},
+		{"Multiple value paths", `emails[type eq "work" and value co "@example.com"] or ims[type eq "xmpp" and value co "@foo.com"]`, `emails[type eq "work" and value co "@example.com"] or ims[type eq "xmpp" and value co "@foo.com"]`},
+
+		{"Redundant grouping", `((userName eq "john"))`, `userName eq "john"`},
+		{"Precedence", `a pr or b pr and c pr`, `a pr or b pr and c pr`},
+		{"Grouped or", `(a pr or b pr) and c pr`, `(a pr or b pr) and c pr`},
+		{"Right grouped", `a pr and (b pr and c pr)`, `a pr and (b pr and c pr)`},
+		{"Keywords are case-insensitive", `a PR AND b Eq TRUE OR NOT (c Lt 5)`, `a pr and b eq true or not (c lt 5)`},
+		{"Literals", `a eq true and b eq false and c eq null and d ge -1.5e3`, `a eq true and b eq false and c eq null and d ge -1.5e3`},
+		{"Zeros", `a eq 0 or b eq -0.05 or c eq 0e1 or d eq 100`, `a eq 0 or b eq -0.05 or c eq 0e1 or d eq 100`},
+		{"Escaped string", `displayName eq "say \"hi\"! <&>"`, `displayName eq "say \"hi\"! <&>"`},
+		{"Ref", `members.$ref eq "https://example.com/v2/Users/1"`, `members.$ref eq "https://example.com/v2/Users/1"`},
+		{"Extension", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value eq "26118915-6090-4610-87e4-49d8ca9f808d"`, `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value eq "26118915-6090-4610-87e4-49d8ca9f808d"`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := Parse(test.inp)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, act.String())
+			// The canonical text must parse to the same tree.
+			again, err := Parse(act.String())
+			require.NoError(t, err)
+			assert.Equal(t, act, again)
+		})
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	act, err := Parse(`urn:ietf:params:scim:schemas:core:2.0:User:name.givenName eq "Barbara" and not (emails[type eq "work"]) or age gt 21`)
+	require.NoError(t, err)
+
+	exp := LogicalExpression{
+		Operator: Or,
+		Left: LogicalExpression{
+			Operator: And,
+			Left: AttributeExpression{
+				Path:     AttributePath{URI: "urn:ietf:params:scim:schemas:core:2.0:User", Name: "name", SubAttribute: "givenName"},
+				Operator: Eq,
+				Value:    "Barbara",
+			},
+			Right: NotExpression{
+				Expression: ValuePathExpression{
+					Path: AttributePath{Name: "emails"},
+					Filter: AttributeExpression{
+						Path:     AttributePath{Name: "type"},
+						Operator: Eq,
+						Value:    "work",
+					},
+				},
+			},
+		},
+		Right: AttributeExpression{
+			Path:     AttributePath{Name: "age"},
+			Operator: Gt,
+			Value:    json.Number("21"),
+		},
+	}
+	assert.Equal(t, exp, act)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		inp    string
+		offset int
+	}{
+		{"Empty", ``, 0},
+		{"Missing value", `userName eq`, 11},
+		{"Unknown operator", `userName is "john"`, 9},
+		{"Unterminated string", `userName eq "john`, 12},
+		{"Invalid escape", `userName eq "jo\hn"`, 12},
+		{"Unquoted value", `userName eq john`, 12},
+		{"Missing right paren", `(userName eq "john"`, 19},
+		{"Extra right paren", `userName eq "john")`, 18},
+		{"Missing right operand", `title pr and`, 12},
+		{"Dangling operator", `title pr and or userName pr`, 13},
+		{"Not without group", `not title pr`, 4},
+		{"Nested value path", `emails[type[value pr]]`, 11},
+		{"Missing right bracket", `emails[type eq "work"`, 21},
+		{"Value path on sub-attribute", `name.givenName[value pr]`, 14},
+		{"Invalid attribute name", `1userName pr`, 0},
+		{"Too many sub-attributes", `name.givenName.first pr`, 0},
+		{"Invalid URI", `foo:userName pr`, 0},
+		{"Invalid character", `userName eq "john" & title pr`, 19},
+		{"Leading zero", `x eq 01`, 5},
+		{"Negative leading zero", `x eq -007`, 5},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.inp)
+			require.Error(t, err)
+			require.IsType(t, SyntaxError{}, err)
+			assert.Equal(t, test.offset, err.(SyntaxError).Offset, err.Error())
+			assert.Equal(t, test.inp, err.(SyntaxError).Filter)
+		})
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	assert.Equal(t, "title pr", MustParse("title pr").String())
+	assert.Panics(t, func() { MustParse("title") })
+}
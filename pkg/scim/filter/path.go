@@ -0,0 +1,84 @@
+package filter
+
+import (
+	"strings"
+)
+
+// Path is a SCIM attribute path as used by the "path" of PATCH operations
+// and by the "attributes" and "excludedAttributes" parameters.  A Path
+// optionally includes a value filter selecting the values of a
+// multi-valued attribute - e.g. emails[type eq "work"].value.
+// https://tools.ietf.org/html/rfc7644#section-3.5.2
+type Path struct {
+	URI          string     //URI is the optional schema URN - e.g. "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User".
+	Name         string     //Name is the attribute's name - e.g. "emails".
+	Filter       Expression //Filter is the optional value filter - e.g. type eq "work".
+	SubAttribute string     //SubAttribute is the optional sub-attribute's name - e.g. "value".
+}
+
+// ParsePath parses an attribute path:
+//
+//	PATH = attrPath / valuePath [subAttr]
+func ParsePath(path string) (Path, error) {
+	tokens, err := lex(path)
+	if err != nil {
+		return Path{}, err
+	}
+	p := &parser{filter: path, tokens: tokens}
+
+	t, err := p.expect(wordToken)
+	if err != nil {
+		return Path{}, err
+	}
+	ap, err := parseAttributePath(t.text)
+	if err != nil {
+		return Path{}, p.errorf(t, "%v", err)
+	}
+	result := Path{URI: ap.URI, Name: ap.Name, SubAttribute: ap.SubAttribute}
+
+	if p.peek().typ == lbracketToken {
+		vp, err := p.parseValuePath(ap, p.next())
+		if err != nil {
+			return Path{}, err
+		}
+		result.Filter = vp.Filter
+		if t := p.peek(); t.typ == wordToken && strings.HasPrefix(t.text, ".") {
+			p.next()
+			if !isAttributeName(t.text[1:]) {
+				return Path{}, p.errorf(t, "invalid sub-attribute name %q", t.text[1:])
+			}
+			result.SubAttribute = t.text[1:]
+		}
+	}
+
+	if t := p.peek(); t.typ != eofToken {
+		return Path{}, p.errorf(t, "unexpected %s after attribute path", t)
+	}
+	return result, nil
+}
+
+// MustParsePath is like ParsePath but panics if the path is malformed.
+func MustParsePath(path string) Path {
+	p, err := ParsePath(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// AttributePath returns the path without its value filter.
+func (p Path) AttributePath() AttributePath {
+	return AttributePath{URI: p.URI, Name: p.Name, SubAttribute: p.SubAttribute}
+}
+
+// String returns the path in its canonical notation.
+func (p Path) String() string {
+	if p.Filter == nil {
+		return p.AttributePath().String()
+	}
+	s := AttributePath{URI: p.URI, Name: p.Name}.String() + "[" + p.Filter.String() + "]"
+	if p.SubAttribute != "" {
+		s += "." + p.SubAttribute
+	}
+	return s
+}
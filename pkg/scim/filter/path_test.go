@@ -0,0 +1,61 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name string
+		inp  string
+		exp  Path
+		str  string
+	}{
+		{"Attribute", "userName", Path{Name: "userName"}, "userName"},
+		{"Sub-attribute", "name.familyName", Path{Name: "name", SubAttribute: "familyName"}, "name.familyName"},
+		{"Extension", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value", Path{URI: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User", Name: "manager", SubAttribute: "value"}, "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value"},
+		{"Value filter", `emails[type eq "work"]`, Path{Name: "emails", Filter: MustParse(`type eq "work"`)}, `emails[type eq "work"]`},
+		{"Value filter and sub-attribute", `emails[type EQ "work" and primary eq true].value`, Path{Name: "emails", Filter: MustParse(`type eq "work" and primary eq true`), SubAttribute: "value"}, `emails[type eq "work" and primary eq true].value`},
+		{"Members", `members[value eq "2819c223-7f76-453a-919d-413861904646"]`, Path{Name: "members", Filter: MustParse(`value eq "2819c223-7f76-453a-919d-413861904646"`)}, `members[value eq "2819c223-7f76-453a-919d-413861904646"]`},
+		{"Extension value filter", `urn:example:2.0:User:badges[level gt 2].name`, Path{URI: "urn:example:2.0:User", Name: "badges", Filter: MustParse(`level gt 2`), SubAttribute: "name"}, `urn:example:2.0:User:badges[level gt 2].name`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := ParsePath(test.inp)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, act)
+			assert.Equal(t, test.str, act.String())
+		})
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		inp    string
+		offset int
+	}{
+		{"Empty", "", 0},
+		{"Filter", `userName eq "bjensen"`, 9},
+		{"Unterminated value filter", `emails[type eq "work"`, 21},
+		{"Nested value filter", `emails[type[value pr]]`, 11},
+		{"Invalid sub-attribute", `emails[type eq "work"].1value`, 22},
+		{"Sub-attribute and value filter", `name.givenName[value pr]`, 14},
+		{"Trailing", `emails[type eq "work"] value`, 23},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParsePath(test.inp)
+			require.IsType(t, SyntaxError{}, err)
+			assert.Equal(t, test.offset, err.(SyntaxError).Offset, err.Error())
+		})
+	}
+	assert.Panics(t, func() { MustParsePath("") })
+}
@@ -0,0 +1,5 @@
+/*
+Package filter parses, builds and prints SCIM filters as defined by
+RFC7644 section 3.4.2.2 - https://tools.ietf.org/html/rfc7644#section-3.4.2.2
+*/
+package filter
@@ -0,0 +1,330 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+)
+
+// Diagnostic describes a single problem found while validating a filter
+// or sortBy attribute against a ResourceType's schemas.
+type Diagnostic struct {
+	Path string //Path is the attribute path (or expression) that the problem was found in.
+	Msg  string //Msg is a human readable description of the problem.
+}
+
+func (d Diagnostic) String() string {
+	return d.Path + ": " + d.Msg
+}
+
+// InvalidFilterError is returned when a filter or sortBy attribute can't
+// be used with a ResourceType.  Every problem found is included in the
+// Diagnostics.
+type InvalidFilterError struct {
+	Filter      string
+	Diagnostics []Diagnostic
+}
+
+func (ife InvalidFilterError) Error() string {
+	msgs := make([]string, len(ife.Diagnostics))
+	for i, d := range ife.Diagnostics {
+		msgs[i] = d.String()
+	}
+	return fmt.Sprintf("invalid filter %q - %s", ife.Filter, strings.Join(msgs, "; "))
+}
+
+// ValidateSearchRequest parses the SearchRequest's filter and checks both
+// the filter and sortBy attribute against the core schema and schema
+// extensions of the provided ResourceType (see ValidateFilter).  A
+// malformed filter results in a filter.SyntaxError.
+func ValidateSearchRequest(sr SearchRequest, rt ResourceType, schemas ...Schema) error {
+	diags := []Diagnostic{}
+	if sr.Filter != "" {
+		expr, err := filter.Parse(sr.Filter)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, newFilterValidator(rt, schemas).validate(expr)...)
+	}
+	if sr.SortBy != "" {
+		diags = append(diags, newFilterValidator(rt, schemas).validateSortBy(sr.SortBy)...)
+	}
+	if len(diags) > 0 {
+		return InvalidFilterError{Filter: sr.Filter, Diagnostics: diags}
+	}
+	return nil
+}
+
+// ValidateFilter checks every attribute path in the filter against the
+// core schema and schema extensions of the provided ResourceType.  Paths
+// that aren't defined by the schemas (including misspelled attributes)
+// and comparisons that can't be performed on an attribute's type (such as
+// "gt" on a boolean) are reported as Diagnostics in an InvalidFilterError.
+// Attributes of schemas that aren't provided aren't checked.
+func ValidateFilter(expr filter.Expression, rt ResourceType, schemas ...Schema) error {
+	diags := newFilterValidator(rt, schemas).validate(expr)
+	if len(diags) > 0 {
+		return InvalidFilterError{Filter: expr.String(), Diagnostics: diags}
+	}
+	return nil
+}
+
+// ValidateSortBy checks that the sortBy attribute is defined by the core
+// schema or schema extensions of the provided ResourceType and that it
+// can be sorted.
+func ValidateSortBy(sortBy string, rt ResourceType, schemas ...Schema) error {
+	diags := newFilterValidator(rt, schemas).validateSortBy(sortBy)
+	if len(diags) > 0 {
+		return InvalidFilterError{Filter: sortBy, Diagnostics: diags}
+	}
+	return nil
+}
+
+// validateQuery checks the search request against the schemas of the
+// SCIM server associated with the provided context (see ValidateQuery).
+func (c Client) validateQuery(ctx context.Context, rt *ResourceType, sr SearchRequest) error {
+	if rt == nil {
+		if sr.Filter == "" {
+			return nil
+		}
+		_, err := filter.Parse(sr.Filter)
+		return err
+	}
+	schemas, err := c.serverSchemas(ctx)
+	if err != nil {
+		return err
+	}
+	return ValidateSearchRequest(sr, *rt, schemas...)
+}
+
+type filterValidator struct {
+	rt      ResourceType
+	schemas map[string]Schema
+}
+
+func newFilterValidator(rt ResourceType, schemas []Schema) filterValidator {
+	fv := filterValidator{
+		rt:      rt,
+		schemas: make(map[string]Schema, len(schemas)),
+	}
+	for _, s := range schemas {
+		fv.schemas[strings.ToLower(s.ID)] = s
+	}
+	return fv
+}
+
+func (fv filterValidator) validate(expr filter.Expression) []Diagnostic {
+	return fv.validateIn(expr, nil)
+}
+
+// validateIn validates the expression - parent is the definition of the
+// attribute whose value filter contains the expression or nil at the
+// top-level of the filter.
+func (fv filterValidator) validateIn(expr filter.Expression, parent *Attribute) []Diagnostic {
+	switch ex := expr.(type) {
+	case filter.LogicalExpression:
+		return append(fv.validateIn(ex.Left, parent), fv.validateIn(ex.Right, parent)...)
+	case filter.NotExpression:
+		return fv.validateIn(ex.Expression, parent)
+	case filter.ValuePathExpression:
+		attr, diags := fv.resolve(ex.Path, parent)
+		if attr == nil {
+			return diags
+		}
+		if attr.Type != Complex {
+			return []Diagnostic{{ex.Path.String(), fmt.Sprintf("value filters require a complex attribute but %s is a %s", ex.Path.Name, attr.Type)}}
+		}
+		return fv.validateIn(ex.Filter, attr)
+	case filter.AttributeExpression:
+		attr, diags := fv.resolve(ex.Path, parent)
+		if attr == nil {
+			return diags
+		}
+		return checkComparison(ex, attr)
+	}
+	return []Diagnostic{{expr.String(), unknownExpressionMessage}}
+}
+
+func (fv filterValidator) validateSortBy(sortBy string) []Diagnostic {
+	expr, err := filter.Parse(sortBy + " pr")
+	ae, ok := expr.(filter.AttributeExpression)
+	if err != nil || !ok {
+		return []Diagnostic{{sortBy, "sortBy must be an attribute path"}}
+	}
+	attr, diags := fv.resolve(ae.Path, nil)
+	if attr == nil {
+		return diags
+	}
+	if attr.Type == Complex && findAttribute(attr.SubAttributes, "value") == nil {
+		return []Diagnostic{{sortBy, fmt.Sprintf("can't sort by complex attribute %s - specify a sub-attribute", attr.Name)}}
+	}
+	return nil
+}
+
+// resolve returns the definition of the attribute identified by the path
+// or, if it isn't defined, the diagnostics explaining why.  A nil
+// definition with no diagnostics indicates that the path can't be
+// checked because its schema wasn't provided.
+func (fv filterValidator) resolve(path filter.AttributePath, parent *Attribute) (*Attribute, []Diagnostic) {
+	var attrs []Attribute
+	switch {
+	case parent != nil:
+		attrs = parent.SubAttributes
+	case path.URI == "" || strings.EqualFold(path.URI, fv.rt.Schema):
+		s, ok := fv.schemas[strings.ToLower(fv.rt.Schema)]
+		if !ok {
+			return nil, nil
+		}
+		attrs = append(append([]Attribute{}, commonAttributes...), s.Attributes...)
+	default:
+		if !fv.isExtension(path.URI) {
+			return nil, []Diagnostic{{path.String(), fmt.Sprintf("%s is not a schema of the %s resource type", path.URI, fv.rt.Name)}}
+		}
+		s, ok := fv.schemas[strings.ToLower(path.URI)]
+		if !ok {
+			return nil, nil
+		}
+		attrs = s.Attributes
+	}
+
+	attr := findAttribute(attrs, path.Name)
+	if attr == nil && parent != nil {
+		return nil, []Diagnostic{{parent.Name + "." + path.String(), unknownAttribute("sub-attribute", path.Name, attrs)}}
+	}
+	if attr == nil {
+		return nil, []Diagnostic{{path.String(), unknownAttribute("attribute", path.Name, attrs)}}
+	}
+	if path.SubAttribute == "" {
+		return attr, nil
+	}
+	if attr.Type != Complex {
+		return nil, []Diagnostic{{path.String(), fmt.Sprintf("%s is a %s attribute and has no sub-attributes", attr.Name, attr.Type)}}
+	}
+	sub := findAttribute(attr.SubAttributes, path.SubAttribute)
+	if sub == nil {
+		return nil, []Diagnostic{{path.String(), unknownAttribute("sub-attribute", path.SubAttribute, attr.SubAttributes)}}
+	}
+	return sub, nil
+}
+
+func (fv filterValidator) isExtension(urn string) bool {
+	for _, se := range fv.rt.SchemaExtensions {
+		if strings.EqualFold(se.Schema, urn) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkComparison reports comparisons that can't be performed on the
+// attribute's type.
+// https://tools.ietf.org/html/rfc7644#section-3.4.2.2
+func checkComparison(ex filter.AttributeExpression, attr *Attribute) []Diagnostic {
+	if ex.Operator == filter.Pr {
+		return nil
+	}
+	path := ex.Path.String()
+	if attr.Type == Complex {
+		// Complex attributes are compared using their "value" sub-attribute.
+		value := findAttribute(attr.SubAttributes, "value")
+		if value == nil {
+			return []Diagnostic{{path, fmt.Sprintf("can't use %s on complex attribute %s - specify a sub-attribute", ex.Operator, attr.Name)}}
+		}
+		attr = value
+	}
+	if ex.Value == nil {
+		if ex.Operator != filter.Eq && ex.Operator != filter.Ne {
+			return []Diagnostic{{path, fmt.Sprintf("can't use %s with null", ex.Operator)}}
+		}
+		return nil
+	}
+
+	switch attr.Type {
+	case Boolean:
+		if ex.Operator != filter.Eq && ex.Operator != filter.Ne {
+			return []Diagnostic{{path, fmt.Sprintf("can't use %s on boolean attribute %s", ex.Operator, attr.Name)}}
+		}
+		if _, ok := ex.Value.(bool); !ok {
+			return []Diagnostic{{path, fmt.Sprintf("%s is a boolean attribute but was compared to %s", attr.Name, filter.FormatValue(ex.Value))}}
+		}
+	case Integer, Decimal:
+		if isSubstringOperator(ex.Operator) {
+			return []Diagnostic{{path, fmt.Sprintf("can't use %s on %s attribute %s", ex.Operator, attr.Type, attr.Name)}}
+		}
+		n, ok := ex.Value.(json.Number)
+		if !ok {
+			return []Diagnostic{{path, fmt.Sprintf("%s is a %s attribute but was compared to %s", attr.Name, attr.Type, filter.FormatValue(ex.Value))}}
+		}
+		if _, err := n.Int64(); attr.Type == Integer && err != nil {
+			return []Diagnostic{{path, fmt.Sprintf("%s is an integer attribute but was compared to %s", attr.Name, n)}}
+		}
+	case DateTime:
+		if isSubstringOperator(ex.Operator) {
+			return []Diagnostic{{path, fmt.Sprintf("can't use %s on dateTime attribute %s", ex.Operator, attr.Name)}}
+		}
+		s, ok := ex.Value.(string)
+		if _, err := time.Parse(time.RFC3339Nano, s); !ok || err != nil {
+			return []Diagnostic{{path, fmt.Sprintf("%s is a dateTime attribute but was compared to %s which isn't an xsd:dateTime", attr.Name, filter.FormatValue(ex.Value))}}
+		}
+	case String, Reference:
+		if _, ok := ex.Value.(string); !ok {
+			return []Diagnostic{{path, fmt.Sprintf("%s is a %s attribute but was compared to %s", attr.Name, attr.Type, filter.FormatValue(ex.Value))}}
+		}
+	}
+	return nil
+}
+
+func isSubstringOperator(op filter.CompareOperator) bool {
+	return op == filter.Co || op == filter.Sw || op == filter.Ew
+}
+
+// unknownAttribute returns a diagnostic message for an undefined
+// attribute that suggests the closest defined attribute name.
+func unknownAttribute(kind string, name string, attrs []Attribute) string {
+	msg := fmt.Sprintf("unknown %s %q", kind, name)
+	best, distance := "", len(name)/2+1
+	for _, a := range attrs {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(a.Name))
+		if d < distance {
+			best, distance = a.Name, d
+		}
+	}
+	if best != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", best)
+	}
+	return msg
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
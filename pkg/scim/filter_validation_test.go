@@ -0,0 +1,157 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFilter(t *testing.T) {
+	schemas := append([]Schema{}, evaluateSchemas...)
+	schemas[0].Attributes = append(schemas[0].Attributes,
+		Attribute{Name: "loginCount", Type: Integer},
+		Attribute{Name: "score", Type: Decimal},
+	)
+
+	tests := []struct {
+		name   string
+		filter string
+		exp    []Diagnostic
+	}{
+		{"Valid", `userName eq "bjensen" and emails[type eq "work" and primary eq true] and meta.lastModified gt "2011-05-13T04:42:34Z"`, nil},
+		{"Case-insensitive names", `USERNAME sw "b" and Emails.Value co "example.com"`, nil},
+		{"Complex with value", `emails co "example.com"`, nil},
+		{"Present complex", `name pr`, nil},
+		{"Numbers", `loginCount gt 5 and score le 1.5`, nil},
+		{"Extension", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value eq "26118915"`, nil},
+		{"Fully qualified core", `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`, nil},
+		{"Null", `title eq null`, nil},
+		{"Schemas", `schemas eq "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`, nil},
+		{"Unavailable schema", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:division eq "x"`, []Diagnostic{
+			{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:division", `unknown attribute "division"`},
+		}},
+		{"Misspelled sub-attribute", `emails.vaule eq "bjensen@example.com"`, []Diagnostic{
+			{"emails.vaule", `unknown sub-attribute "vaule" (did you mean "value"?)`},
+		}},
+		{"Misspelled attribute", `usrName eq "bjensen"`, []Diagnostic{
+			{"usrName", `unknown attribute "usrName" (did you mean "userName"?)`},
+		}},
+		{"Unknown attribute", `favoriteColor eq "blue"`, []Diagnostic{
+			{"favoriteColor", `unknown attribute "favoriteColor"`},
+		}},
+		{"Sub-attribute of simple attribute", `userName.value eq "x"`, []Diagnostic{
+			{"userName.value", "userName is a string attribute and has no sub-attributes"},
+		}},
+		{"Foreign schema", `urn:example:scim:schemas:extension:Custom:color eq "blue"`, []Diagnostic{
+			{"urn:example:scim:schemas:extension:Custom:color", "urn:example:scim:schemas:extension:Custom is not a schema of the User resource type"},
+		}},
+		{"Boolean ordering", `active gt false`, []Diagnostic{
+			{"active", "can't use gt on boolean attribute active"},
+		}},
+		{"Boolean compared to string", `active eq "true"`, []Diagnostic{
+			{"active", `active is a boolean attribute but was compared to "true"`},
+		}},
+		{"Complex without value", `name eq "Barbara"`, []Diagnostic{
+			{"name", "can't use eq on complex attribute name - specify a sub-attribute"},
+		}},
+		{"Value filter on simple attribute", `userName[value pr]`, []Diagnostic{
+			{"userName", "value filters require a complex attribute but userName is a string"},
+		}},
+		{"Value filter sub-attributes", `emails[typo eq "work"]`, []Diagnostic{
+			{"emails.typo", `unknown sub-attribute "typo" (did you mean "type"?)`},
+		}},
+		{"Integer compared to decimal", `loginCount gt 1.5`, []Diagnostic{
+			{"loginCount", "loginCount is an integer attribute but was compared to 1.5"},
+		}},
+		{"Contains on number", `score co 1`, []Diagnostic{
+			{"score", "can't use co on decimal attribute score"},
+		}},
+		{"Invalid dateTime", `meta.created gt "yesterday"`, []Diagnostic{
+			{"meta.created", `created is a dateTime attribute but was compared to "yesterday" which isn't an xsd:dateTime`},
+		}},
+		{"String compared to number", `userName eq 5`, []Diagnostic{
+			{"userName", "userName is a string attribute but was compared to 5"},
+		}},
+		{"Ordering null", `title gt null`, []Diagnostic{
+			{"title", "can't use gt with null"},
+		}},
+		{"All problems", `usrName eq "x" or not (active ge true)`, []Diagnostic{
+			{"usrName", `unknown attribute "usrName" (did you mean "userName"?)`},
+			{"active", "can't use ge on boolean attribute active"},
+		}},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateFilter(filter.MustParse(test.filter), UserResourceType, schemas...)
+			if test.exp == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.IsType(t, InvalidFilterError{}, err)
+			assert.Equal(t, test.exp, err.(InvalidFilterError).Diagnostics)
+		})
+	}
+}
+
+func TestValidateFilterWithoutSchemas(t *testing.T) {
+	assert.NoError(t, ValidateFilter(filter.MustParse(`anything gt true`), UserResourceType))
+}
+
+func TestValidateSortBy(t *testing.T) {
+	assert.NoError(t, ValidateSortBy("name.familyName", UserResourceType, evaluateSchemas...))
+	assert.NoError(t, ValidateSortBy("emails", UserResourceType, evaluateSchemas...))
+	assert.EqualError(t, ValidateSortBy("name", UserResourceType, evaluateSchemas...), `invalid filter "name" - name: can't sort by complex attribute name - specify a sub-attribute`)
+	assert.EqualError(t, ValidateSortBy("name.familyNam", UserResourceType, evaluateSchemas...), `invalid filter "name.familyNam" - name.familyNam: unknown sub-attribute "familyNam" (did you mean "familyName"?)`)
+	assert.EqualError(t, ValidateSortBy("name eq", UserResourceType, evaluateSchemas...), `invalid filter "name eq" - name eq: sortBy must be an attribute path`)
+}
+
+func TestValidateSearchRequest(t *testing.T) {
+	sr := SearchRequest{Filter: `userName eq "bjensen"`, SortBy: "title"}
+	assert.NoError(t, ValidateSearchRequest(sr, UserResourceType, evaluateSchemas...))
+
+	sr = SearchRequest{Filter: `emails.vaule eq "bjensen@example.com"`, SortBy: "titel"}
+	err := ValidateSearchRequest(sr, UserResourceType, evaluateSchemas...)
+	assert.EqualError(t, err, `invalid filter "emails.vaule eq \"bjensen@example.com\"" - emails.vaule: unknown sub-attribute "vaule" (did you mean "value"?); titel: unknown attribute "titel" (did you mean "title"?)`)
+
+	sr = SearchRequest{Filter: `schemas eq "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`}
+	assert.NoError(t, ValidateSearchRequest(sr, UserResourceType, evaluateSchemas...))
+
+	sr = SearchRequest{Filter: `userName eq`}
+	assert.IsType(t, filter.SyntaxError{}, ValidateSearchRequest(sr, UserResourceType, evaluateSchemas...))
+}
+
+func TestValidateQuery(t *testing.T) {
+	schemas, err := json.Marshal(evaluateSchemas)
+	require.NoError(t, err)
+	requests := map[string]int{}
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests[req.URL.Path]++
+			if req.URL.Path == "/v2/Schemas" {
+				return jsonResponse(200, string(schemas)), nil
+			}
+			return jsonResponse(200, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:ListResponse"],"totalResults":0,"Resources":[]}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/v2")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = c.QueryResourceType(ctx, UserResourceType, SearchRequest{Filter: `usrName eq "bjensen"`}, ValidateQuery())
+	assert.EqualError(t, err, `invalid filter "usrName eq \"bjensen\"" - usrName: unknown attribute "usrName" (did you mean "userName"?)`)
+	_, err = c.QueryResourceType(ctx, UserResourceType, SearchRequest{Filter: `userName eq "bjensen"`, SortBy: "title"}, ValidateQuery())
+	assert.NoError(t, err)
+	_, err = c.QueryServer(ctx, SearchRequest{Filter: `userName eq`}, ValidateQuery())
+	assert.IsType(t, filter.SyntaxError{}, err)
+	_, err = c.QueryServer(ctx, SearchRequest{Filter: `favoriteColor eq "blue"`}, ValidateQuery())
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"/v2/Schemas": 1, "/v2/Users/.search": 1, "/v2/.search": 1}, requests)
+}
@@ -32,7 +32,7 @@ var GroupResourceType = ResourceType{
 	Name:        "Group",
 	Endpoint:    "/Groups",
 	Description: "SCIM ResourceType - See https://tools.ietf.org/html/rfc7643#section-6",
-	Schema:      ResourceTypeURN,
+	Schema:      GroupURN,
 }
 
 //URN returns the IANA registered SCIM name for the User data structure
@@ -55,14 +55,14 @@ func (g Group) ResourceType() ResourceType {
 
 // MarshalJSON implements https://golang.org/pkg/encoding/json/#Marshaler
 func (g Group) MarshalJSON() ([]byte, error) {
-	type Alias Group
+	type groupAlias Group
 	json := ap.ConfigCompatibleWithStandardLibrary
-	return json.Marshal((Alias)(g))
+	return json.Marshal((groupAlias)(g))
 }
 
 // UnmarshalJSON implements https://golang.org/pkg/encoding/json/#Unmarshaler
 func (g *Group) UnmarshalJSON(data []byte) error {
-	type Alias Group
+	type groupAlias Group
 	json := ap.ConfigCompatibleWithStandardLibrary
-	return json.Unmarshal(data, (*Alias)(g))
+	return json.Unmarshal(data, (*groupAlias)(g))
 }
@@ -0,0 +1,101 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// MarshalForWrite returns the JSON representation of the resource that
+// should be sent to create or replace it (see RFC7644 sections 3.3 and
+// 3.5.1).  The attributes (and sub-attributes) that the provided schemas
+// define as readOnly - including the id and meta attributes common to all
+// resources - are omitted since the server assigns them, writeOnly
+// attributes are omitted if they're empty (servers never return them, so
+// they're unassigned in any resource that was retrieved) and immutable
+// attributes are sent as they are in the resource (i.e. as received from
+// the server) since a replacement must not change them.
+func MarshalForWrite(res Resource, schemas ...Schema) ([]byte, error) {
+	doc, err := document(res)
+	if err != nil {
+		return nil, err
+	}
+	e := newEvaluator(res, schemas)
+	writable(doc, e.root(doc).attrs)
+	for _, se := range e.extensions {
+		if ext, ok := lookup(doc, se.Schema).(map[string]interface{}); ok {
+			writable(ext, e.schemas[strings.ToLower(se.Schema)].Attributes)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// writable removes the attributes of the object that shouldn't be sent to
+// the server.
+func writable(obj map[string]interface{}, attrs []Attribute) {
+	for k, v := range obj {
+		attr := findAttribute(attrs, k)
+		switch {
+		case attr == nil:
+		case attr.Mutability == ReadOnly:
+			delete(obj, k)
+		case attr.Mutability == WriteOnly && isEmpty(v):
+			delete(obj, k)
+		case attr.Type == Complex:
+			for _, cv := range asSlice(v) {
+				if sub, ok := cv.(map[string]interface{}); ok {
+					writable(sub, attr.SubAttributes)
+				}
+			}
+		}
+	}
+}
+
+// marshalForWrite marshals the resource for a create or replace request
+// using the schemas of the SCIM server associated with the provided
+// context (see MarshalForWrite).
+func (c Client) marshalForWrite(ctx context.Context, res Resource) ([]byte, error) {
+	schemas, err := c.schemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rj, err := MarshalForWrite(res, schemas...)
+	if err != nil {
+		return nil, CodecError{
+			Err: err.Error(),
+			Op:  Marshal,
+		}
+	}
+	return rj, nil
+}
+
+// schemas returns the schemas of the SCIM server associated with the
+// provided context as retrieved by GetSchemas, falling back to the core
+// schemas for those that the server doesn't publish (or for all of them if
+// the server's schemas haven't been retrieved).
+func (c Client) schemas(ctx context.Context) ([]Schema, error) {
+	url, err := c.serviceURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var discovered []Schema
+	if v, ok := c.discovered.Load(url); ok {
+		discovered = v.([]Schema)
+	}
+	schemas := append([]Schema{}, discovered...)
+	for _, core := range CoreSchemas() {
+		if !containsSchema(discovered, core.ID) {
+			schemas = append(schemas, core)
+		}
+	}
+	return schemas, nil
+}
+
+func containsSchema(schemas []Schema, urn string) bool {
+	for _, s := range schemas {
+		if strings.EqualFold(s.ID, urn) {
+			return true
+		}
+	}
+	return false
+}
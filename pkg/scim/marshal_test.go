@@ -0,0 +1,103 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalForWrite(t *testing.T) {
+	u := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &u))
+	u.Groups = []GroupRef{{Value: "e9e30dba", Multivalued: Multivalued{Display: "Tour Guides"}}}
+	eu := EnterpriseUser{}
+	require.NoError(t, u.GetExtension(&eu))
+	eu.Manager.DisplayName = "John Smith"
+	require.NoError(t, u.UpdateExtension(&eu))
+
+	b, err := MarshalForWrite(&u, CoreSchemas()...)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"],
+		"externalId": "701984",
+		"userName": "bjensen@example.com",
+		"name": {"familyName": "Jensen", "givenName": "Barbara"},
+		"title": "Tour Guide",
+		"userType": "Employee",
+		"active": true,
+		"emails": [
+			{"value": "bjensen@example.com", "type": "work", "primary": true},
+			{"value": "babs@jensen.org", "type": "home"}
+		],
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {
+			"employeeNumber": "701984",
+			"costCenter": "4130",
+			"manager": {"value": "26118915-6090-4610-87e4-49d8ca9f808d", "$ref": ""}
+		}
+	}`, string(b))
+}
+
+func TestMarshalForWriteMutability(t *testing.T) {
+	schemas := []Schema{{
+		CommonAttributes: CommonAttributes{ID: GroupURN},
+		Attributes: []Attribute{
+			{Name: "displayName", Type: String, Mutability: Immutable},
+			{Name: "members", Type: Complex, Multivalued: true, SubAttributes: []Attribute{
+				{Name: "value", Type: String, Mutability: Immutable},
+				{Name: "display", Type: String, Mutability: ReadOnly},
+				{Name: "type", Type: String, Mutability: WriteOnly},
+			}},
+		},
+	}}
+	g := Group{
+		CommonAttributes: CommonAttributes{ID: "e9e30dba", Schemas: []string{GroupURN}},
+		DisplayName:      "Tour Guides",
+		Members: []MemberRef{
+			{Value: "2819c223", Multivalued: Multivalued{Display: "Babs Jensen", Type: "User"}},
+			{Value: "902c246b", Multivalued: Multivalued{Display: "Mandy Pepperidge"}},
+		},
+	}
+
+	b, err := MarshalForWrite(&g, schemas...)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+		"displayName": "Tour Guides",
+		"members": [{"value": "2819c223", "type": "User"}, {"value": "902c246b"}]
+	}`, string(b))
+}
+
+func TestReplaceResourceUsesDiscoveredSchemas(t *testing.T) {
+	var body string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet {
+				return jsonResponse(200, `[{"id":"urn:ietf:params:scim:schemas:core:2.0:User","attributes":[{"name":"userName","type":"string"},{"name":"title","type":"string","mutability":"readOnly"}]}]`), nil
+			}
+			b, _ := ioutil.ReadAll(req.Body)
+			body = string(b)
+			return jsonResponse(200, body), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/v2", DisableEtag(true))
+	require.NoError(t, err)
+	u := User{
+		CommonAttributes: CommonAttributes{ID: "2819c223", Schemas: []string{UserURN}},
+		UserName:         "bjensen@example.com",
+		Title:            "Tour Guide",
+	}
+
+	require.NoError(t, c.ReplaceResource(context.Background(), &u))
+	assert.JSONEq(t, `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"bjensen@example.com","title":"Tour Guide"}`, body)
+
+	_, err = c.GetSchemas(context.Background())
+	require.NoError(t, err)
+	u.ID = "2819c223"
+	require.NoError(t, c.ReplaceResource(context.Background(), &u))
+	assert.JSONEq(t, `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"bjensen@example.com"}`, body)
+}
@@ -0,0 +1,150 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/PennState/httputil/pkg/httperror"
+	"github.com/PennState/scim-client/pkg/scim/filter"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultMaxMembersPerPatch is the number of members added or removed by
+// a single PATCH request.
+const defaultMaxMembersPerPatch = 100
+
+// AddMembers adds the provided members to the Group with the provided id.
+// If the SCIM server supports PATCH, the members are added using "add"
+// operations, in requests of no more than 100 members each.  Otherwise
+// the group is retrieved, modified and replaced using Update.
+func (c Client) AddMembers(ctx context.Context, groupID string, refs ...MemberRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	patch, err := c.patchSupported(ctx)
+	if err != nil {
+		return err
+	}
+	if patch {
+		err = c.patchMembers(ctx, groupID, len(refs), func(start, end int) []PatchOperation {
+			return []PatchOperation{{
+				Op:    PatchAdd,
+				Path:  "members",
+				Value: refs[start:end],
+			}}
+		})
+		if !isPatchUnsupported(err) {
+			return err
+		}
+		log.Debug("PATCH is not supported - falling back to replacing the group: ", err)
+	}
+
+	return c.Update(ctx, &Group{}, groupID, func(res Resource) error {
+		g := res.(*Group)
+		existing := make(map[string]bool, len(g.Members))
+		for _, m := range g.Members {
+			existing[m.Value] = true
+		}
+		for _, ref := range refs {
+			if !existing[ref.Value] {
+				existing[ref.Value] = true
+				g.Members = append(g.Members, ref)
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveMembers removes the members with the provided ids from the Group
+// with the provided id.  If the SCIM server supports PATCH, the members
+// are removed using "remove" operations with members[value eq "..."]
+// paths, in requests of no more than 100 members each.  Otherwise the
+// group is retrieved, modified and replaced using Update.
+func (c Client) RemoveMembers(ctx context.Context, groupID string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	patch, err := c.patchSupported(ctx)
+	if err != nil {
+		return err
+	}
+	if patch {
+		err = c.patchMembers(ctx, groupID, len(ids), func(start, end int) []PatchOperation {
+			ops := make([]PatchOperation, 0, end-start)
+			for _, id := range ids[start:end] {
+				ops = append(ops, PatchOperation{
+					Op:   PatchRemove,
+					Path: filter.Attr("members").Where(filter.Attr("value").Eq(id)).String(),
+				})
+			}
+			return ops
+		})
+		if !isPatchUnsupported(err) {
+			return err
+		}
+		log.Debug("PATCH is not supported - falling back to replacing the group: ", err)
+	}
+
+	return c.Update(ctx, &Group{}, groupID, func(res Resource) error {
+		g := res.(*Group)
+		removed := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			removed[id] = true
+		}
+		members := g.Members[:0]
+		for _, m := range g.Members {
+			if !removed[m.Value] {
+				members = append(members, m)
+			}
+		}
+		g.Members = members
+		return nil
+	})
+}
+
+// patchMembers sends PATCH requests for chunks of count members using the
+// operations returned by ops for each chunk.
+func (c Client) patchMembers(ctx context.Context, groupID string, count int, ops func(start, end int) []PatchOperation) error {
+	for start := 0; start < count; start += defaultMaxMembersPerPatch {
+		end := start + defaultMaxMembersPerPatch
+		if end > count {
+			end = count
+		}
+		g := &Group{
+			CommonAttributes: CommonAttributes{
+				ID: groupID,
+			},
+		}
+		err := c.ModifyResource(ctx, g, NewPatchOp(ops(start, end)...))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchSupported indicates whether the SCIM server supports PATCH.  When
+// server discovery is disabled, PATCH is assumed to be supported.
+func (c Client) patchSupported(ctx context.Context) (bool, error) {
+	spc, err := c.serviceProviderConfig(ctx)
+	if err != nil || spc == nil {
+		return true, err
+	}
+	return spc.PatchConfig.Supported, nil
+}
+
+// isPatchUnsupported indicates whether the error was caused by the SCIM
+// server not implementing PATCH.
+func isPatchUnsupported(err error) bool {
+	var status int
+	switch e := err.(type) {
+	case ErrorResponse:
+		status, _ = strconv.Atoi(e.Status)
+	case httperror.HTTPError:
+		status = e.Code
+	}
+	return status == http.StatusNotImplemented || status == http.StatusMethodNotAllowed
+}
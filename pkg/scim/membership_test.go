@@ -0,0 +1,134 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const membershipGroup = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+	"id": "e9e30dba",
+	"displayName": "Tour Guides",
+	"members": [{"value": "a"}, {"value": "b"}],
+	"meta": {"resourceType": "Group", "version": "W/\"1\""}
+}`
+
+// membershipServer records the requests made to it and responds to
+// PATCH requests with the provided status.
+type membershipServer struct {
+	patchStatus int
+	spc         string
+	patches     []PatchOp
+	put         *Group
+	ifMatch     string
+}
+
+func (ms *membershipServer) client(t *testing.T, opts ...ClientOpt) *Client {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body := []byte{}
+			if req.Body != nil {
+				body, _ = ioutil.ReadAll(req.Body)
+			}
+			switch {
+			case strings.HasSuffix(req.URL.Path, "/ServiceProviderConfig"):
+				return jsonResponse(200, ms.spc), nil
+			case req.Method == "PATCH":
+				po := PatchOp{}
+				require.NoError(t, json.Unmarshal(body, &po))
+				ms.patches = append(ms.patches, po)
+				if ms.patchStatus != 200 {
+					return jsonResponse(ms.patchStatus, fmt.Sprintf(`{"status":"%d"}`, ms.patchStatus)), nil
+				}
+				return jsonResponse(204, ""), nil
+			case req.Method == "PUT":
+				ms.put = &Group{}
+				ms.ifMatch = req.Header.Get("If-Match")
+				require.NoError(t, json.Unmarshal(body, ms.put))
+				return jsonResponse(200, string(body)), nil
+			default:
+				return jsonResponse(200, membershipGroup), nil
+			}
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim", opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func memberRefs(count int) []MemberRef {
+	refs := make([]MemberRef, count)
+	for i := range refs {
+		refs[i] = MemberRef{Value: fmt.Sprintf("m%03d", i)}
+	}
+	return refs
+}
+
+func TestAddMembersWithPatch(t *testing.T) {
+	ms := &membershipServer{patchStatus: 200}
+	c := ms.client(t, DisableDiscovery(true))
+	require.NoError(t, c.AddMembers(context.Background(), "e9e30dba", memberRefs(250)...))
+
+	require.Len(t, ms.patches, 3)
+	for i, size := range []int{100, 100, 50} {
+		po := ms.patches[i]
+		assert.Equal(t, []string{PatchOpURN}, po.Schemas)
+		require.Len(t, po.Operations, 1)
+		assert.Equal(t, PatchAdd, po.Operations[0].Op)
+		assert.Equal(t, "members", po.Operations[0].Path)
+		assert.Len(t, po.Operations[0].Value, size)
+	}
+	assert.Nil(t, ms.put)
+}
+
+func TestRemoveMembersWithPatch(t *testing.T) {
+	ms := &membershipServer{patchStatus: 200}
+	c := ms.client(t, DisableDiscovery(true))
+	require.NoError(t, c.RemoveMembers(context.Background(), "e9e30dba", "a", "b\"c"))
+
+	require.Len(t, ms.patches, 1)
+	assert.Equal(t, []PatchOperation{
+		{Op: PatchRemove, Path: `members[value eq "a"]`},
+		{Op: PatchRemove, Path: `members[value eq "b\"c"]`},
+	}, ms.patches[0].Operations)
+}
+
+func TestMembersWithoutPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		patchStatus int
+		spc         string
+		discovery   bool
+		patches     int
+	}{
+		{"PATCH not supported by ServiceProviderConfig", 200, `{"patch":{"supported":false}}`, true, 0},
+		{"PATCH not implemented", 501, "", false, 1},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			ms := &membershipServer{patchStatus: test.patchStatus, spc: test.spc}
+			c := ms.client(t, DisableDiscovery(!test.discovery))
+			require.NoError(t, c.AddMembers(context.Background(), "e9e30dba", MemberRef{Value: "b"}, MemberRef{Value: "c"}))
+			assert.Len(t, ms.patches, test.patches)
+			require.NotNil(t, ms.put)
+			assert.Equal(t, "W/\"1\"", ms.ifMatch)
+			assert.Equal(t, []MemberRef{{Value: "a"}, {Value: "b"}, {Value: "c"}}, ms.put.Members)
+
+			ms.patches, ms.put = nil, nil
+			require.NoError(t, c.RemoveMembers(context.Background(), "e9e30dba", "a"))
+			assert.Len(t, ms.patches, test.patches)
+			require.NotNil(t, ms.put)
+			assert.Equal(t, []MemberRef{{Value: "b"}}, ms.put.Members)
+		})
+	}
+}
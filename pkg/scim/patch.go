@@ -0,0 +1,504 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+)
+
+// ApplyPatch applies the PatchOp's operations to the resource as a SCIM
+// server would (see RFC7644 section 3.5.2):
+//
+//   - add sets singular attributes, merges the sub-attributes of complex
+//     attributes and appends values (that aren't already present) to
+//     multi-valued attributes.
+//   - remove unassigns the target attribute, sub-attribute or, with a
+//     value filter, the matching values.  A value (as sent by some
+//     servers' clients) removes the multi-valued attribute's equal values.
+//   - replace sets the target, merging the sub-attributes of singular
+//     complex attributes and replacing every value of multi-valued
+//     attributes (or, with a value filter, the matching values).
+//
+// Operations without a path apply each attribute of their value.  When a
+// value marked primary is added or replaced, the other values of the
+// attribute lose their primary flag.  If the resource's schemas are
+// provided, paths are resolved against them and the attributes'
+// mutability, required flag and type are enforced.
+//
+// The operations are applied atomically - if any operation fails, the
+// resource is unchanged and an ErrorResponse is returned with the
+// ScimType (invalidPath, noTarget, mutability, invalidValue or
+// invalidSyntax) that a SCIM server would return.
+func ApplyPatch(res Resource, po PatchOp, schemas ...Schema) error {
+	doc, err := document(res)
+	if err != nil {
+		return err
+	}
+	p := patcher{
+		evaluator: newEvaluator(res, schemas),
+		rt:        res.ResourceType(),
+		defs:      schemas,
+	}
+	for i, op := range po.Operations {
+		err := p.apply(doc, op)
+		if er, ok := err.(ErrorResponse); ok {
+			er.Detail = fmt.Sprintf("Operations[%d]: %s", i, er.Detail)
+			return er
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err = restore(res, doc)
+	if ce, ok := err.(CodecError); ok {
+		return badRequest(ScimTypeInvalidValue, ce.Err)
+	}
+	return err
+}
+
+type patcher struct {
+	evaluator
+	rt   ResourceType
+	defs []Schema
+}
+
+func (p patcher) apply(doc map[string]interface{}, op PatchOperation) error {
+	typ := PatchOpType(strings.ToLower(string(op.Op)))
+	if typ != PatchAdd && typ != PatchRemove && typ != PatchReplace {
+		return badRequest(ScimTypeInvalidSyntax, fmt.Sprintf("unknown operation %q", op.Op))
+	}
+	value, err := generic(op.Value)
+	if err != nil {
+		return badRequest(ScimTypeInvalidValue, err.Error())
+	}
+
+	if op.Path == "" {
+		if typ == PatchRemove {
+			return badRequest(ScimTypeNoTarget, "remove operations require a path")
+		}
+		return p.applyObject(doc, typ, value)
+	}
+	path, err := filter.ParsePath(op.Path)
+	if err != nil {
+		return badRequest(ScimTypeInvalidPath, err.Error())
+	}
+	if typ != PatchRemove && value == nil {
+		return badRequest(ScimTypeInvalidValue, fmt.Sprintf("%s operations require a value", typ))
+	}
+	return p.applyPath(doc, typ, path, value)
+}
+
+// applyObject applies each attribute of the value of an operation without
+// a path.
+func (p patcher) applyObject(doc map[string]interface{}, typ PatchOpType, value interface{}) error {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return badRequest(ScimTypeInvalidValue, fmt.Sprintf("the value of %s operations without a path must be an object", typ))
+	}
+	for _, k := range sortedKeys(obj) {
+		path, err := filter.ParsePath(k)
+		if err != nil {
+			return badRequest(ScimTypeInvalidPath, err.Error())
+		}
+		// Extensions are applied attribute by attribute so that
+		// unmentioned extension attributes are left unchanged (a key can
+		// only name an entire extension if its URI isn't an extension).
+		ext, isObj := obj[k].(map[string]interface{})
+		if urn, ok := extensionPath(path); ok && isObj && !p.isExtension(path.URI) {
+			for _, name := range sortedKeys(ext) {
+				err := p.applyPath(doc, typ, filter.Path{URI: urn, Name: name}, ext[name])
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		err = p.applyPath(doc, typ, path, obj[k])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p patcher) applyPath(doc map[string]interface{}, typ PatchOpType, path filter.Path, value interface{}) error {
+	attr, parent, err := p.resolve(path)
+	if err != nil {
+		return err
+	}
+	t := p.target(doc, path, typ != PatchRemove)
+	if t.obj == nil {
+		// Removing an attribute of a missing extension is a no-op.
+		return nil
+	}
+	cur := t.obj[t.key]
+	urn, whole := extensionPath(path)
+	whole = whole && strings.EqualFold(t.key, urn)
+
+	if attr != nil {
+		err := checkMutability(typ, path, attr, parent, cur)
+		if err != nil {
+			return err
+		}
+		if typ != PatchRemove {
+			err = checkValue(path, attr, value, path.Filter == nil && path.SubAttribute == "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch {
+	case path.Filter != nil:
+		return p.applyFiltered(t, typ, path, value)
+	case path.SubAttribute != "":
+		return applySubAttribute(t, typ, path, value)
+	case typ == PatchRemove:
+		if arr, ok := cur.([]interface{}); ok && value != nil {
+			t.obj[t.key] = without(arr, value)
+			if len(t.obj[t.key].([]interface{})) == 0 {
+				delete(t.obj, t.key)
+			}
+			return nil
+		}
+		delete(t.obj, t.key)
+		if whole {
+			removeSchema(doc, urn)
+		}
+		return nil
+	}
+	if whole {
+		p.addSchema(doc, urn)
+	}
+
+	multi := t.multi || (attr != nil && attr.Multivalued)
+	if _, ok := cur.([]interface{}); ok {
+		multi = true
+	}
+	if _, ok := value.([]interface{}); ok && attr == nil {
+		multi = true
+	}
+	if !multi {
+		t.obj[t.key] = merge(cur, value)
+		return nil
+	}
+
+	values := asSlice(value)
+	if typ == PatchAdd {
+		for _, v := range values {
+			if !contains(asSlice(cur), v) {
+				cur = append(asSlice(cur), v)
+			}
+		}
+		values = asSlice(cur)
+	}
+	t.obj[t.key] = exclusivePrimary(values, asSlice(value))
+	return nil
+}
+
+// applyFiltered applies an operation to the values of a multi-valued
+// attribute selected by a value filter.
+func (p patcher) applyFiltered(t target, typ PatchOpType, path filter.Path, value interface{}) error {
+	indexes, err := t.matching(p.evaluator, path.Filter)
+	if err != nil {
+		return badRequest(ScimTypeInvalidFilter, err.Error())
+	}
+	if len(indexes) == 0 {
+		return noTarget(path)
+	}
+
+	values := t.values()
+	_, multi := t.obj[t.key].([]interface{})
+	switch {
+	case typ == PatchRemove && path.SubAttribute == "":
+		remaining := []interface{}{}
+		for idx, v := range values {
+			if !containsInt(indexes, idx) {
+				remaining = append(remaining, v)
+			}
+		}
+		values = remaining
+	case typ == PatchRemove:
+		for _, idx := range indexes {
+			obj := values[idx].(map[string]interface{})
+			delete(obj, keyOf(obj, path.SubAttribute))
+		}
+	case path.SubAttribute == "":
+		for _, idx := range indexes {
+			if typ == PatchAdd {
+				values[idx] = merge(values[idx], value)
+			} else {
+				values[idx] = value
+			}
+		}
+		values = exclusivePrimary(values, changed(values, indexes))
+	default:
+		for _, idx := range indexes {
+			obj := values[idx].(map[string]interface{})
+			k := keyOf(obj, path.SubAttribute)
+			obj[k] = merge(obj[k], value)
+		}
+		values = exclusivePrimary(values, changed(values, indexes))
+	}
+
+	switch {
+	case len(values) == 0:
+		delete(t.obj, t.key)
+	case multi:
+		t.obj[t.key] = values
+	default:
+		t.obj[t.key] = values[0]
+	}
+	return nil
+}
+
+// applySubAttribute applies an operation to a sub-attribute of a complex
+// attribute (or of every value of a multi-valued complex attribute).
+func applySubAttribute(t target, typ PatchOpType, path filter.Path, value interface{}) error {
+	cur := t.obj[t.key]
+	objs := []map[string]interface{}{}
+	switch c := cur.(type) {
+	case []interface{}:
+		for _, v := range c {
+			if obj, ok := v.(map[string]interface{}); ok {
+				objs = append(objs, obj)
+			}
+		}
+	case map[string]interface{}:
+		objs = append(objs, c)
+	case nil:
+		if typ == PatchRemove {
+			return nil
+		}
+		obj := map[string]interface{}{}
+		t.obj[t.key] = obj
+		if t.multi {
+			t.obj[t.key] = []interface{}{obj}
+		}
+		objs = append(objs, obj)
+	default:
+		return invalidPath(path, "%s isn't a complex attribute", path.Name)
+	}
+
+	for _, obj := range objs {
+		k := keyOf(obj, path.SubAttribute)
+		if typ == PatchRemove {
+			delete(obj, k)
+			continue
+		}
+		obj[k] = merge(obj[k], value)
+	}
+	if obj, ok := cur.(map[string]interface{}); ok && typ == PatchRemove && len(obj) == 0 {
+		delete(t.obj, t.key)
+	}
+	return nil
+}
+
+// resolve returns the definitions of the path's target attribute and of
+// its parent (for sub-attribute paths) if the resource's schemas were
+// provided.
+func (p patcher) resolve(path filter.Path) (*Attribute, *Attribute, error) {
+	if len(p.defs) == 0 {
+		return nil, nil, nil
+	}
+	attr, err := ResolvePath(path, p.rt, p.defs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path.SubAttribute == "" {
+		return &attr, nil, nil
+	}
+	parent, err := ResolvePath(filter.Path{URI: path.URI, Name: path.Name}, p.rt, p.defs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &attr, &parent, nil
+}
+
+// checkMutability returns an error if the operation isn't compatible with
+// the target attribute's mutability or required flag.
+// https://tools.ietf.org/html/rfc7643#section-7
+func checkMutability(typ PatchOpType, path filter.Path, attr, parent *Attribute, cur interface{}) error {
+	for _, a := range []*Attribute{parent, attr} {
+		if a == nil {
+			continue
+		}
+		switch {
+		case a.Mutability == ReadOnly:
+			return badRequest(ScimTypeMutability, fmt.Sprintf("%s is readOnly", path))
+		case a.Mutability == Immutable && a == attr && (typ == PatchRemove || !isEmpty(cur)):
+			return badRequest(ScimTypeMutability, fmt.Sprintf("%s is immutable", path))
+		}
+	}
+	if typ == PatchRemove && attr.Required && path.Filter == nil {
+		return badRequest(ScimTypeInvalidValue, fmt.Sprintf("%s is required", path))
+	}
+	return nil
+}
+
+// checkValue returns an error if the value isn't compatible with the
+// attribute's type.  whole indicates whether the value is for the entire
+// (possibly multi-valued) attribute rather than a single value.
+func checkValue(path filter.Path, attr *Attribute, value interface{}, whole bool) error {
+	values := []interface{}{value}
+	if arr, ok := value.([]interface{}); ok && whole {
+		if !attr.Multivalued {
+			return badRequest(ScimTypeInvalidValue, fmt.Sprintf("%s is single-valued but was given multiple values", path))
+		}
+		values = arr
+	}
+	for _, v := range values {
+		ok := true
+		switch attr.Type {
+		case Boolean:
+			_, ok = v.(bool)
+		case Integer, Decimal:
+			_, ok = v.(json.Number)
+		case String, Reference, DateTime, Binary:
+			_, ok = v.(string)
+		case Complex:
+			_, ok = v.(map[string]interface{})
+		}
+		if !ok && v != nil {
+			return badRequest(ScimTypeInvalidValue, fmt.Sprintf("%s is a %s attribute but was given %v", path, attr.Type, v))
+		}
+	}
+	return nil
+}
+
+//
+// Value helpers
+//
+
+// merge returns the value that results from adding the value to the
+// current value - complex values are merged sub-attribute by
+// sub-attribute, other values are replaced.
+func merge(cur, value interface{}) interface{} {
+	curObj, ok := cur.(map[string]interface{})
+	obj, isObj := value.(map[string]interface{})
+	if !ok || !isObj {
+		return value
+	}
+	merged := make(map[string]interface{}, len(curObj)+len(obj))
+	for k, v := range curObj {
+		merged[k] = v
+	}
+	for k, v := range obj {
+		merged[keyOf(merged, k)] = v
+	}
+	return merged
+}
+
+func asSlice(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case nil:
+		return []interface{}{}
+	case []interface{}:
+		return val
+	default:
+		return []interface{}{val}
+	}
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, e := range values {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func changed(values []interface{}, indexes []int) []interface{} {
+	c := make([]interface{}, len(indexes))
+	for i, idx := range indexes {
+		c[i] = values[idx]
+	}
+	return c
+}
+
+func containsInt(values []int, v int) bool {
+	for _, e := range values {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns the values that aren't equal to (or, for complex values,
+// don't have the same "value" sub-attribute as) the removed values.
+func without(values []interface{}, removed interface{}) []interface{} {
+	remaining := []interface{}{}
+	for _, v := range values {
+		match := false
+		for _, r := range asSlice(removed) {
+			vo, vok := v.(map[string]interface{})
+			ro, rok := r.(map[string]interface{})
+			if vok && rok && lookup(ro, "value") != nil {
+				match = match || reflect.DeepEqual(lookup(vo, "value"), lookup(ro, "value"))
+				continue
+			}
+			match = match || reflect.DeepEqual(v, r)
+		}
+		if !match {
+			remaining = append(remaining, v)
+		}
+	}
+	return remaining
+}
+
+// exclusivePrimary clears the primary flag of every value except the last
+// of the changed values that is marked primary.
+// https://tools.ietf.org/html/rfc7643#section-2.4
+func exclusivePrimary(values []interface{}, changed []interface{}) []interface{} {
+	var primary interface{}
+	for _, c := range changed {
+		if obj, ok := c.(map[string]interface{}); ok && lookup(obj, "primary") == true {
+			primary = c
+		}
+	}
+	if primary == nil {
+		return values
+	}
+	found := false
+	for i := len(values) - 1; i >= 0; i-- {
+		obj, ok := values[i].(map[string]interface{})
+		if !ok || lookup(obj, "primary") != true {
+			continue
+		}
+		if !found && reflect.DeepEqual(obj, primary) {
+			found = true
+			continue
+		}
+		cleared := merge(obj, map[string]interface{}{keyOf(obj, "primary"): false})
+		values[i] = cleared
+	}
+	return values
+}
+
+func removeSchema(doc map[string]interface{}, urn string) {
+	schemas, _ := doc["schemas"].([]interface{})
+	remaining := []interface{}{}
+	for _, s := range schemas {
+		if str, ok := s.(string); !ok || !strings.EqualFold(str, urn) {
+			remaining = append(remaining, s)
+		}
+	}
+	if schemas != nil {
+		doc["schemas"] = remaining
+	}
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,284 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		ops   []PatchOperation
+		check func(*testing.T, User, EnterpriseUser)
+	}{
+		{"Add singular", []PatchOperation{
+			{Op: PatchAdd, Path: "nickName", Value: "Babs"},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, "Babs", u.NickName)
+		}},
+		{"Add complex merges", []PatchOperation{
+			{Op: PatchAdd, Path: "name", Value: map[string]interface{}{"middleName": "J"}},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, &Name{FamilyName: "Jensen", GivenName: "Barbara", MiddleName: "J"}, u.Name)
+		}},
+		{"Add multi-valued appends", []PatchOperation{
+			{Op: PatchAdd, Path: "emails", Value: []Email{
+				{Value: "babs@jensen.org", Multivalued: Multivalued{Type: "home"}},
+				{Value: "barbara@example.org", Multivalued: Multivalued{Type: "other", Primary: true}},
+			}},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, []Email{
+				{Value: "bjensen@example.com", Multivalued: Multivalued{Type: "work"}},
+				{Value: "babs@jensen.org", Multivalued: Multivalued{Type: "home"}},
+				{Value: "barbara@example.org", Multivalued: Multivalued{Type: "other", Primary: true}},
+			}, u.Emails)
+		}},
+		{"Add without path", []PatchOperation{
+			{Op: "Add", Value: map[string]interface{}{
+				"nickName": "Babs",
+				EnterpriseUserURN: map[string]interface{}{
+					"department": "Tour Operations",
+				},
+			}},
+		}, func(t *testing.T, u User, eu EnterpriseUser) {
+			assert.Equal(t, "Babs", u.NickName)
+			assert.Equal(t, "Tour Operations", eu.Department)
+			assert.Equal(t, "4130", eu.CostCenter)
+		}},
+		{"Add sub-attribute with filter", []PatchOperation{
+			{Op: PatchAdd, Path: `emails[type eq "home"].display`, Value: "Babs"},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, "Babs", u.Emails[1].Display)
+			assert.Empty(t, u.Emails[0].Display)
+		}},
+		{"Remove", []PatchOperation{
+			{Op: PatchRemove, Path: "title"},
+			{Op: PatchRemove, Path: "nickName"},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Empty(t, u.Title)
+		}},
+		{"Remove with filter", []PatchOperation{
+			{Op: PatchRemove, Path: `emails[type eq "work"]`},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, []Email{{Value: "babs@jensen.org", Multivalued: Multivalued{Type: "home"}}}, u.Emails)
+		}},
+		{"Remove sub-attribute with filter", []PatchOperation{
+			{Op: PatchRemove, Path: `emails[type eq "work"].primary`},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.False(t, u.Emails[0].Primary)
+			assert.Equal(t, "bjensen@example.com", u.Emails[0].Value)
+		}},
+		{"Remove with value", []PatchOperation{
+			{Op: PatchRemove, Path: "emails", Value: []interface{}{map[string]interface{}{"value": "babs@jensen.org"}}},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Len(t, u.Emails, 1)
+			assert.Equal(t, "bjensen@example.com", u.Emails[0].Value)
+		}},
+		{"Remove extension attribute", []PatchOperation{
+			{Op: PatchRemove, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value"},
+			{Op: PatchRemove, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:costCenter"},
+		}, func(t *testing.T, u User, eu EnterpriseUser) {
+			assert.Empty(t, eu.Manager)
+			assert.Empty(t, eu.CostCenter)
+			assert.Equal(t, "701984", eu.EmployeeNumber)
+		}},
+		{"Remove extension", []PatchOperation{
+			{Op: PatchRemove, Path: EnterpriseUserURN},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, []string{UserURN}, u.Schemas)
+			assert.Empty(t, u.AdditionalProperties)
+		}},
+		{"Replace complex merges", []PatchOperation{
+			{Op: PatchReplace, Path: "name", Value: map[string]interface{}{"givenName": "Babs"}},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, &Name{FamilyName: "Jensen", GivenName: "Babs"}, u.Name)
+		}},
+		{"Replace multi-valued", []PatchOperation{
+			{Op: PatchReplace, Path: "emails", Value: Email{Value: "babs@example.org"}},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, []Email{{Value: "babs@example.org"}}, u.Emails)
+		}},
+		{"Replace with filter", []PatchOperation{
+			{Op: PatchReplace, Path: `emails[type eq "home"]`, Value: map[string]interface{}{"value": "babs@example.org", "type": "home", "primary": true}},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.Equal(t, []Email{
+				{Value: "bjensen@example.com", Multivalued: Multivalued{Type: "work"}},
+				{Value: "babs@example.org", Multivalued: Multivalued{Type: "home", Primary: true}},
+			}, u.Emails)
+		}},
+		{"Replace primary sub-attribute", []PatchOperation{
+			{Op: PatchReplace, Path: `emails[value eq "babs@jensen.org"].primary`, Value: true},
+		}, func(t *testing.T, u User, _ EnterpriseUser) {
+			assert.False(t, u.Emails[0].Primary)
+			assert.True(t, u.Emails[1].Primary)
+		}},
+		{"Replace without path", []PatchOperation{
+			{Op: PatchReplace, Value: map[string]interface{}{
+				"title":                        "Senior Tour Guide",
+				"active":                       false,
+				EnterpriseUserURN + ":manager": map[string]interface{}{"value": "62f9b4d4"},
+			}},
+		}, func(t *testing.T, u User, eu EnterpriseUser) {
+			assert.Equal(t, "Senior Tour Guide", u.Title)
+			assert.False(t, u.Active)
+			assert.Equal(t, "62f9b4d4", eu.Manager.Value)
+		}},
+		{"Replace adds extension", []PatchOperation{
+			{Op: PatchRemove, Path: EnterpriseUserURN},
+			{Op: PatchReplace, Path: EnterpriseUserURN + ":division", Value: "Theme Park"},
+		}, func(t *testing.T, u User, eu EnterpriseUser) {
+			assert.Contains(t, u.Schemas, EnterpriseUserURN)
+			assert.Equal(t, EnterpriseUser{Division: "Theme Park"}, eu)
+		}},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			u := User{}
+			require.NoError(t, json.Unmarshal([]byte(evaluateUser), &u))
+
+			require.NoError(t, ApplyPatch(&u, NewPatchOp(test.ops...)))
+			eu := EnterpriseUser{}
+			if _, ok := u.AdditionalProperties[EnterpriseUserURN]; ok {
+				require.NoError(t, u.GetExtension(&eu))
+			}
+			test.check(t, u, eu)
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	schemas := append([]Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "userName", Type: String, Required: true},
+			{Name: "title", Type: String},
+			{Name: "active", Type: Boolean},
+			{Name: "userType", Type: String, Mutability: Immutable},
+			{Name: "emails", Type: Complex, Multivalued: true, SubAttributes: []Attribute{
+				{Name: "value", Type: String},
+				{Name: "type", Type: String},
+			}},
+			{Name: "groups", Type: Complex, Multivalued: true, Mutability: ReadOnly},
+		},
+	}}, evaluateSchemas[1])
+
+	tests := []struct {
+		name     string
+		op       PatchOperation
+		scimType string
+	}{
+		{"Unknown operation", PatchOperation{Op: "move", Path: "title"}, ScimTypeInvalidSyntax},
+		{"Malformed path", PatchOperation{Op: PatchAdd, Path: "emails[type eq", Value: "x"}, ScimTypeInvalidPath},
+		{"Unknown attribute", PatchOperation{Op: PatchAdd, Path: "titel", Value: "x"}, ScimTypeInvalidPath},
+		{"Remove without path", PatchOperation{Op: PatchRemove}, ScimTypeNoTarget},
+		{"No matching values", PatchOperation{Op: PatchReplace, Path: `emails[type eq "other"].value`, Value: "x"}, ScimTypeNoTarget},
+		{"Read-only", PatchOperation{Op: PatchReplace, Path: "id", Value: "x"}, ScimTypeMutability},
+		{"Read-only multi-valued", PatchOperation{Op: PatchAdd, Path: "groups", Value: map[string]interface{}{"value": "x"}}, ScimTypeMutability},
+		{"Immutable", PatchOperation{Op: PatchReplace, Path: "userType", Value: "x"}, ScimTypeMutability},
+		{"Required", PatchOperation{Op: PatchRemove, Path: "userName"}, ScimTypeInvalidValue},
+		{"Missing value", PatchOperation{Op: PatchAdd, Path: "title"}, ScimTypeInvalidValue},
+		{"Wrong type", PatchOperation{Op: PatchReplace, Path: "active", Value: "False"}, ScimTypeInvalidValue},
+		{"Multiple values", PatchOperation{Op: PatchReplace, Path: "title", Value: []string{"a", "b"}}, ScimTypeInvalidValue},
+		{"Value isn't an object", PatchOperation{Op: PatchReplace, Value: "x"}, ScimTypeInvalidValue},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			u := User{}
+			require.NoError(t, json.Unmarshal([]byte(evaluateUser), &u))
+			orig := u
+
+			err := ApplyPatch(&u, NewPatchOp(PatchOperation{Op: PatchAdd, Path: "title", Value: "Senior Tour Guide"}, test.op), schemas...)
+			require.IsType(t, ErrorResponse{}, err)
+			er := err.(ErrorResponse)
+			assert.Equal(t, test.scimType, er.ScimType)
+			assert.Equal(t, "400", er.Status)
+			assert.Contains(t, er.Detail, "Operations[1]: ")
+			assert.Equal(t, orig, u, "the resource must be unchanged")
+		})
+	}
+}
+
+func TestApplyPatchImmutableUnassigned(t *testing.T) {
+	schemas := []Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes:       []Attribute{{Name: "userType", Type: String, Mutability: Immutable}},
+	}}
+	u := User{}
+	require.NoError(t, ApplyPatch(&u, NewPatchOp(PatchOperation{Op: PatchAdd, Path: "userType", Value: "Permanent"}), schemas...))
+	assert.Equal(t, "Permanent", u.UserType)
+}
+
+func TestApplyPatchDiff(t *testing.T) {
+	from := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &from))
+	to := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &to))
+	to.Title = ""
+	to.NickName = "Babs"
+	to.Name.GivenName = "Babs"
+	to.Emails = []Email{
+		{Value: "bjensen@example.com", Multivalued: Multivalued{Type: "other"}},
+		{Value: "barbara@example.org", Multivalued: Multivalued{Type: "home", Primary: true}},
+	}
+	eu := EnterpriseUser{}
+	require.NoError(t, to.GetExtension(&eu))
+	eu.CostCenter = ""
+	eu.Department = "Tour Operations"
+	require.NoError(t, to.UpdateExtension(&eu))
+
+	po, err := Diff(&from, &to)
+	require.NoError(t, err)
+	require.NoError(t, ApplyPatch(&from, po))
+
+	po, err = Diff(&from, &to)
+	require.NoError(t, err)
+	assert.Empty(t, po.Operations)
+}
+
+func TestApplyPatchBinary(t *testing.T) {
+	schemas := []Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes:       []Attribute{{Name: "title", Type: Binary}},
+	}}
+	u := User{}
+	require.NoError(t, ApplyPatch(&u, NewPatchOp(PatchOperation{Op: PatchAdd, Path: "title", Value: "TUlJRE1EQ0NB"}), schemas...))
+	assert.Equal(t, "TUlJRE1EQ0NB", u.Title)
+
+	err := ApplyPatch(&u, NewPatchOp(PatchOperation{Op: PatchReplace, Path: "title", Value: 5}), schemas...)
+	require.IsType(t, ErrorResponse{}, err)
+	assert.Equal(t, ScimTypeInvalidValue, err.(ErrorResponse).ScimType)
+	assert.Equal(t, "TUlJRE1EQ0NB", u.Title)
+}
+
+func TestApplyPatchMissingMultiValued(t *testing.T) {
+	for _, schemas := range [][]Schema{nil, CoreSchemas()} {
+		u := User{UserName: "bjensen"}
+		u.Schemas = []string{UserURN}
+		require.NoError(t, ApplyPatch(&u, NewPatchOp(PatchOperation{Op: PatchAdd, Path: "emails.value", Value: "bjensen@example.com"}), schemas...))
+		assert.Equal(t, []Email{{Value: "bjensen@example.com"}}, u.Emails)
+		assert.Equal(t, "bjensen", u.UserName)
+
+		u = User{UserName: "bjensen"}
+		u.Schemas = []string{UserURN}
+		require.NoError(t, ApplyPatch(&u, NewPatchOp(PatchOperation{Op: PatchReplace, Path: "emails", Value: map[string]interface{}{"value": "bjensen@example.com"}}), schemas...))
+		assert.Equal(t, []Email{{Value: "bjensen@example.com"}}, u.Emails)
+	}
+}
+
+func TestApplyPatchUndecodable(t *testing.T) {
+	u := User{UserName: "bjensen"}
+	u.Schemas = []string{UserURN}
+	orig := u
+
+	err := ApplyPatch(&u, NewPatchOp(PatchOperation{Op: PatchReplace, Path: "userName", Value: map[string]interface{}{"value": "x"}}))
+	require.IsType(t, ErrorResponse{}, err)
+	assert.Equal(t, ScimTypeInvalidValue, err.(ErrorResponse).ScimType)
+	assert.Equal(t, orig, u, "the resource must be unchanged")
+}
@@ -0,0 +1,405 @@
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+)
+
+// ResolvePath returns the definition of the attribute identified by the
+// path using the core schema and schema extensions of the provided
+// ResourceType.  A path naming a schema extension (e.g.
+// "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User") resolves
+// to a complex attribute whose sub-attributes are the extension's
+// attributes.  The path's value filter, if any, is validated against the
+// attribute's sub-attributes.  Paths that can't be resolved result in an
+// ErrorResponse with the invalidPath ScimType.
+func ResolvePath(path filter.Path, rt ResourceType, schemas ...Schema) (Attribute, error) {
+	fv := newFilterValidator(rt, schemas)
+	if urn, ok := extensionPath(path); ok && fv.isExtension(urn) {
+		s, ok := fv.schemas[strings.ToLower(urn)]
+		if !ok {
+			return Attribute{}, invalidPath(path, "schema %s is not available", urn)
+		}
+		return Attribute{Name: urn, Type: Complex, SubAttributes: s.Attributes}, nil
+	}
+
+	ap := path.AttributePath()
+	ap.SubAttribute = ""
+	attr, diags := fv.resolve(ap, nil)
+	if attr == nil && len(diags) == 0 {
+		return Attribute{}, invalidPath(path, "the schema of %s is not available", path)
+	}
+	if path.Filter != nil && attr != nil {
+		if attr.Type != Complex {
+			diags = append(diags, Diagnostic{ap.String(), fmt.Sprintf("value filters require a complex attribute but %s is a %s", attr.Name, attr.Type)})
+		} else {
+			diags = append(diags, fv.validateIn(path.Filter, attr)...)
+		}
+	}
+	if attr != nil && path.SubAttribute != "" {
+		sub := findAttribute(attr.SubAttributes, path.SubAttribute)
+		switch {
+		case attr.Type != Complex:
+			diags = append(diags, Diagnostic{path.String(), fmt.Sprintf("%s is a %s attribute and has no sub-attributes", attr.Name, attr.Type)})
+		case sub == nil:
+			diags = append(diags, Diagnostic{path.String(), unknownAttribute("sub-attribute", path.SubAttribute, attr.SubAttributes)})
+		}
+		attr = sub
+	}
+	if len(diags) > 0 {
+		msgs := make([]string, len(diags))
+		for i, d := range diags {
+			msgs[i] = d.String()
+		}
+		return Attribute{}, invalidPath(path, "%s", strings.Join(msgs, "; "))
+	}
+	return *attr, nil
+}
+
+// GetPath returns the value at the provided path of the resource using
+// the generic JSON representation (map[string]interface{} for complex
+// values, []interface{} for multi-valued attributes, json.Number for
+// numbers).  Paths with a value filter return the matching values (or
+// their sub-attribute) as a []interface{}.  Unassigned attributes result
+// in a nil value.  The schemas are used to evaluate value filters (see
+// Match).
+func GetPath(res Resource, path filter.Path, schemas ...Schema) (interface{}, error) {
+	doc, err := document(res)
+	if err != nil {
+		return nil, err
+	}
+	e := newEvaluator(res, schemas)
+	t := e.target(doc, path, false)
+	if t.obj == nil {
+		return nil, nil
+	}
+
+	v := t.obj[t.key]
+	if path.Filter != nil {
+		matches := []interface{}{}
+		indexes, err := t.matching(e, path.Filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range indexes {
+			mv := t.values()[idx]
+			if path.SubAttribute != "" {
+				mv = lookup(mv.(map[string]interface{}), path.SubAttribute)
+				if mv == nil {
+					continue
+				}
+			}
+			matches = append(matches, mv)
+		}
+		if len(matches) == 0 {
+			return nil, nil
+		}
+		return matches, nil
+	}
+	if path.SubAttribute == "" {
+		return v, nil
+	}
+	if arr, ok := v.([]interface{}); ok {
+		subs := []interface{}{}
+		for _, av := range arr {
+			if obj, ok := av.(map[string]interface{}); ok && lookup(obj, path.SubAttribute) != nil {
+				subs = append(subs, lookup(obj, path.SubAttribute))
+			}
+		}
+		if len(subs) == 0 {
+			return nil, nil
+		}
+		return subs, nil
+	}
+	if obj, ok := v.(map[string]interface{}); ok {
+		return lookup(obj, path.SubAttribute), nil
+	}
+	return nil, nil
+}
+
+// SetPath replaces the value at the provided path of the resource.  The
+// value can be any Go value that encodes to the attribute's JSON
+// representation.  A path naming a sub-attribute of a multi-valued
+// attribute (without a value filter) sets the sub-attribute of every
+// value while a path with a value filter replaces the matching values
+// (or their sub-attribute) and results in an ErrorResponse with the
+// noTarget ScimType if no values match.  A missing multi-valued
+// attribute is created with the value as its only element.  Extension
+// attributes are stored in the resource's AdditionalProperties and the
+// extension's URN is added to the resource's schemas.  The resource is
+// left unchanged if an error is returned.
+func SetPath(res Resource, path filter.Path, value interface{}, schemas ...Schema) error {
+	doc, err := document(res)
+	if err != nil {
+		return err
+	}
+	v, err := generic(value)
+	if err != nil {
+		return err
+	}
+	e := newEvaluator(res, schemas)
+	t := e.target(doc, path, true)
+	urn, whole := extensionPath(path)
+	whole = whole && strings.EqualFold(t.key, urn)
+
+	switch {
+	case path.Filter != nil:
+		indexes, err := t.matching(e, path.Filter)
+		if err != nil {
+			return err
+		}
+		if len(indexes) == 0 {
+			return noTarget(path)
+		}
+		values := t.values()
+		for _, idx := range indexes {
+			if path.SubAttribute == "" {
+				values[idx] = v
+				continue
+			}
+			obj := values[idx].(map[string]interface{})
+			obj[keyOf(obj, path.SubAttribute)] = v
+		}
+		if _, ok := t.obj[t.key].([]interface{}); ok {
+			t.obj[t.key] = values
+		} else {
+			t.obj[t.key] = values[0]
+		}
+	case path.SubAttribute != "":
+		cur := t.obj[t.key]
+		if arr, ok := cur.([]interface{}); ok {
+			for _, av := range arr {
+				if obj, ok := av.(map[string]interface{}); ok {
+					obj[keyOf(obj, path.SubAttribute)] = v
+				}
+			}
+			break
+		}
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			obj = map[string]interface{}{}
+		}
+		obj[keyOf(obj, path.SubAttribute)] = v
+		t.obj[t.key] = obj
+		if t.multi && !ok {
+			t.obj[t.key] = []interface{}{obj}
+		}
+	default:
+		t.obj[t.key] = v
+		if _, ok := v.([]interface{}); t.multi && v != nil && !ok {
+			t.obj[t.key] = []interface{}{v}
+		}
+		if whole {
+			e.addSchema(doc, urn)
+		}
+	}
+
+	return restore(res, doc)
+}
+
+//
+// Document targets
+//
+
+// target is the location of an attribute within a resource's document.
+type target struct {
+	obj   map[string]interface{} //obj is the object containing the attribute (nil if it doesn't exist).
+	key   string                 //key is the attribute's key in obj.
+	attrs []Attribute            //attrs are the definitions of the attribute's sub-attributes.
+	multi bool                   //multi indicates whether the attribute is multi-valued.
+}
+
+// values returns the attribute's values as a slice - a singular complex
+// value is returned as a slice with one element.
+func (t target) values() []interface{} {
+	switch v := t.obj[t.key].(type) {
+	case []interface{}:
+		return v
+	case nil:
+		return nil
+	default:
+		return []interface{}{v}
+	}
+}
+
+// matching returns the indexes of the attribute's values that match the
+// provided value filter.
+func (t target) matching(e evaluator, expr filter.Expression) ([]int, error) {
+	indexes := []int{}
+	for idx, v := range t.values() {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ok, err := e.match(expr, scope{obj: obj, attrs: t.attrs})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			indexes = append(indexes, idx)
+		}
+	}
+	return indexes, nil
+}
+
+// target locates the attribute identified by the path within the
+// document.  If create is true, a missing extension object is added to
+// the document (along with its URN in the document's schemas).
+func (e evaluator) target(doc map[string]interface{}, path filter.Path, create bool) target {
+	s := e.root(doc)
+	if urn, ok := extensionPath(path); ok && (lookup(doc, urn) != nil || e.hasSchema(urn) || e.isExtension(urn)) {
+		return target{obj: doc, key: keyOf(doc, urn), attrs: e.schemas[strings.ToLower(urn)].Attributes}
+	}
+
+	obj, attrs, urn := s.obj, s.attrs, e.urn
+	if path.URI != "" && !strings.EqualFold(path.URI, e.urn) {
+		urn = path.URI
+		ext, ok := lookup(doc, path.URI).(map[string]interface{})
+		if !ok && create {
+			ext = map[string]interface{}{}
+			doc[keyOf(doc, path.URI)] = ext
+			e.addSchema(doc, path.URI)
+		}
+		obj, attrs = ext, e.schemas[strings.ToLower(path.URI)].Attributes
+	}
+
+	t := target{obj: obj}
+	if obj != nil {
+		t.key = keyOf(obj, path.Name)
+	}
+	if attr := findAttribute(attrs, path.Name); attr != nil {
+		t.attrs = attr.SubAttributes
+		t.multi = attr.Multivalued
+	} else if cs, ok := CoreSchema(urn); ok {
+		// Without the resource's schemas, the core schemas still tell
+		// whether a missing attribute has to be created as an array.
+		if attr := findAttribute(cs.Attributes, path.Name); attr != nil {
+			t.multi = attr.Multivalued
+		}
+	}
+	return t
+}
+
+func (e evaluator) hasSchema(urn string) bool {
+	_, ok := e.schemas[strings.ToLower(urn)]
+	return ok
+}
+
+func (e evaluator) isExtension(urn string) bool {
+	for _, se := range e.extensions {
+		if strings.EqualFold(se.Schema, urn) {
+			return true
+		}
+	}
+	return false
+}
+
+// extensionPath returns the URN that the path would identify if it named
+// an entire schema extension - the parser can't distinguish
+// "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User" from an
+// attribute named "User" of the "...:2.0" schema.
+func extensionPath(path filter.Path) (string, bool) {
+	if path.URI == "" || path.Filter != nil || path.SubAttribute != "" {
+		return "", false
+	}
+	return path.URI + ":" + path.Name, true
+}
+
+// keyOf returns the key of the object that case-insensitively matches the
+// provided name or the name if there is no such key.
+func keyOf(obj map[string]interface{}, name string) string {
+	if _, ok := obj[name]; ok {
+		return name
+	}
+	for k := range obj {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+// addSchema adds the extension's URN to the document's schemas unless
+// it's already listed.  The core schema's URN is added first if the
+// document doesn't list any schemas (e.g. a resource that was never sent
+// to the server) since servers reject resources without it.
+func (e evaluator) addSchema(doc map[string]interface{}, urn string) {
+	schemas, _ := doc["schemas"].([]interface{})
+	for _, s := range schemas {
+		if str, ok := s.(string); ok && strings.EqualFold(str, urn) {
+			return
+		}
+	}
+	if len(schemas) == 0 {
+		schemas = append(schemas, e.urn)
+	}
+	doc["schemas"] = append(schemas, urn)
+}
+
+// generic returns the generic JSON representation of the provided value.
+func generic(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&v)
+	return v, err
+}
+
+// restore replaces the contents of the resource with the provided
+// document.  The resource is left unchanged if the document can't be
+// decoded.
+func restore(res Resource, doc map[string]interface{}) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(res)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return CodecError{
+			Err:  fmt.Sprintf("can't restore a resource of type %T", res),
+			Op:   Unmarshal,
+			Body: b,
+		}
+	}
+	fresh := reflect.New(v.Elem().Type())
+	err = json.Unmarshal(b, fresh.Interface())
+	if err != nil {
+		return CodecError{
+			Err:  err.Error(),
+			Op:   Unmarshal,
+			Body: b,
+		}
+	}
+	v.Elem().Set(fresh.Elem())
+	return nil
+}
+
+func invalidPath(path filter.Path, format string, a ...interface{}) error {
+	return badRequest(ScimTypeInvalidPath, fmt.Sprintf("invalid path %q: ", path.String())+fmt.Sprintf(format, a...))
+}
+
+func noTarget(path filter.Path) error {
+	return badRequest(ScimTypeNoTarget, fmt.Sprintf("no values match path %q", path.String()))
+}
+
+// badRequest returns the error a SCIM server would return for an invalid
+// request.
+func badRequest(scimType string, detail string) error {
+	return ErrorResponse{
+		Schemas:  []string{ErrorResponseURN},
+		ScimType: scimType,
+		Detail:   detail,
+		Status:   strconv.Itoa(http.StatusBadRequest),
+	}
+}
@@ -0,0 +1,175 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		exp  string
+		typ  Type
+		err  string
+	}{
+		{"Attribute", "userName", "userName", String, ""},
+		{"Case-insensitive", "EMAILS.Value", "value", String, ""},
+		{"Common attribute", "meta.lastModified", "lastModified", DateTime, ""},
+		{"Fully qualified", "urn:ietf:params:scim:schemas:core:2.0:User:name.givenName", "givenName", String, ""},
+		{"Extension attribute", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value", "value", String, ""},
+		{"Extension", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User", EnterpriseUserURN, Complex, ""},
+		{"Value filter", `emails[type eq "work"]`, "emails", Complex, ""},
+		{"Value filter and sub-attribute", `emails[type eq "work"].primary`, "primary", Boolean, ""},
+		{"Unknown attribute", "usrName", "", "", `invalid path "usrName": usrName: unknown attribute "usrName" (did you mean "userName"?)`},
+		{"Unknown sub-attribute", `emails[type eq "work"].vaule`, "", "", `invalid path "emails[type eq \"work\"].vaule": emails[type eq "work"].vaule: unknown sub-attribute "vaule" (did you mean "value"?)`},
+		{"Invalid value filter", `emails[typ eq "work"]`, "", "", `invalid path "emails[typ eq \"work\"]": emails.typ: unknown sub-attribute "typ" (did you mean "type"?)`},
+		{"Value filter on simple attribute", `userName[value pr]`, "", "", `invalid path "userName[value pr]": userName: value filters require a complex attribute but userName is a string`},
+		{"Foreign schema", "urn:example:Custom:color", "", "", `invalid path "urn:example:Custom:color": urn:example:Custom:color: urn:example:Custom is not a schema of the User resource type`},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			attr, err := ResolvePath(filter.MustParsePath(test.path), UserResourceType, evaluateSchemas...)
+			if test.err != "" {
+				require.IsType(t, ErrorResponse{}, err)
+				assert.Equal(t, ScimTypeInvalidPath, err.(ErrorResponse).ScimType)
+				assert.Equal(t, test.err, err.(ErrorResponse).Detail)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, attr.Name)
+			assert.Equal(t, test.typ, attr.Type)
+		})
+	}
+
+	_, err := ResolvePath(filter.MustParsePath("userName"), UserResourceType)
+	assert.Error(t, err)
+}
+
+func TestGetPath(t *testing.T) {
+	user := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &user))
+
+	tests := []struct {
+		name string
+		path string
+		exp  interface{}
+	}{
+		{"Attribute", "userName", "bjensen@example.com"},
+		{"Case-insensitive", "USERNAME", "bjensen@example.com"},
+		{"Boolean", "active", true},
+		{"Sub-attribute", "name.familyName", "Jensen"},
+		{"Multi-valued sub-attribute", "emails.type", []interface{}{"work", "home"}},
+		{"Value filter", `emails[type eq "work"]`, []interface{}{map[string]interface{}{"value": "bjensen@example.com", "type": "work", "primary": true}}},
+		{"Value filter and sub-attribute", `emails[type eq "home"].value`, []interface{}{"babs@jensen.org"}},
+		{"Value filter without match", `emails[type eq "other"].value`, nil},
+		{"Unassigned", "nickName", nil},
+		{"Extension attribute", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber", "701984"},
+		{"Extension sub-attribute", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value", "26118915-6090-4610-87e4-49d8ca9f808d"},
+		{"Missing extension", "urn:example:2.0:Badge:level", nil},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			act, err := GetPath(&user, filter.MustParsePath(test.path), evaluateSchemas...)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, act)
+		})
+	}
+
+	ext, err := GetPath(&user, filter.MustParsePath(EnterpriseUserURN))
+	require.NoError(t, err)
+	assert.Equal(t, "4130", ext.(map[string]interface{})["costCenter"])
+}
+
+func TestSetPath(t *testing.T) {
+	user := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &user))
+
+	require.NoError(t, SetPath(&user, filter.MustParsePath("displayName"), "Babs Jensen"))
+	assert.Equal(t, "Babs Jensen", user.DisplayName)
+
+	require.NoError(t, SetPath(&user, filter.MustParsePath("name.givenName"), "Babs"))
+	assert.Equal(t, "Babs", user.Name.GivenName)
+	assert.Equal(t, "Jensen", user.Name.FamilyName)
+
+	require.NoError(t, SetPath(&user, filter.MustParsePath(`emails[type eq "home"].value`), "babs@example.org", evaluateSchemas...))
+	assert.Equal(t, "bjensen@example.com", user.Emails[0].Value)
+	assert.Equal(t, "babs@example.org", user.Emails[1].Value)
+
+	require.NoError(t, SetPath(&user, filter.MustParsePath("emails.primary"), false))
+	assert.False(t, user.Emails[0].Primary)
+
+	err := SetPath(&user, filter.MustParsePath(`emails[type eq "other"].value`), "x")
+	require.IsType(t, ErrorResponse{}, err)
+	assert.Equal(t, ScimTypeNoTarget, err.(ErrorResponse).ScimType)
+
+	require.NoError(t, SetPath(&user, filter.MustParsePath("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.displayName"), "John Smith"))
+	eu := EnterpriseUser{}
+	require.NoError(t, user.GetExtension(&eu))
+	assert.Equal(t, "John Smith", eu.Manager.DisplayName)
+	assert.Equal(t, "26118915-6090-4610-87e4-49d8ca9f808d", eu.Manager.Value)
+	assert.Equal(t, "701984", eu.EmployeeNumber)
+
+	// Unchanged attributes survive the round-trip.
+	assert.Equal(t, "2819c223-7f76-453a-919d-413861904646", user.ID)
+	assert.Equal(t, "W/\"3694e05e9dff591\"", user.Meta.Version)
+}
+
+func TestSetPathAddsExtension(t *testing.T) {
+	user := User{UserName: "bjensen"}
+	user.Schemas = []string{UserURN}
+
+	require.NoError(t, SetPath(&user, filter.MustParsePath("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber"), "701984"))
+	eu := EnterpriseUser{}
+	require.NoError(t, user.GetExtension(&eu))
+	assert.Equal(t, "701984", eu.EmployeeNumber)
+	assert.Equal(t, []string{UserURN, EnterpriseUserURN}, user.Schemas)
+
+	require.NoError(t, SetPath(&user, filter.MustParsePath(EnterpriseUserURN), EnterpriseUser{Department: "Tour Operations"}))
+	eu = EnterpriseUser{}
+	require.NoError(t, user.GetExtension(&eu))
+	assert.Equal(t, "", eu.EmployeeNumber)
+	assert.Equal(t, "Tour Operations", eu.Department)
+}
+
+func TestSetPathOnNewResource(t *testing.T) {
+	user := User{UserName: "bjensen"}
+	require.NoError(t, SetPath(&user, filter.MustParsePath(EnterpriseUserURN+":employeeNumber"), "701984"))
+	assert.Equal(t, []string{UserURN, EnterpriseUserURN}, user.Schemas)
+
+	user = User{UserName: "bjensen"}
+	require.NoError(t, SetPath(&user, filter.MustParsePath(EnterpriseUserURN), EnterpriseUser{Department: "Tour Operations"}))
+	assert.Equal(t, []string{UserURN, EnterpriseUserURN}, user.Schemas)
+	assert.NoError(t, Validate(&user, CoreSchemas()...))
+}
+
+func TestSetPathMissingMultiValued(t *testing.T) {
+	for _, schemas := range [][]Schema{nil, CoreSchemas()} {
+		user := User{UserName: "bjensen"}
+		user.Schemas = []string{UserURN}
+		require.NoError(t, SetPath(&user, filter.MustParsePath("emails.value"), "bjensen@example.com", schemas...))
+		assert.Equal(t, []Email{{Value: "bjensen@example.com"}}, user.Emails)
+		assert.Equal(t, "bjensen", user.UserName)
+
+		user = User{UserName: "bjensen"}
+		require.NoError(t, SetPath(&user, filter.MustParsePath("emails"), Email{Value: "bjensen@example.com"}, schemas...))
+		assert.Len(t, user.Emails, 1)
+	}
+}
+
+func TestSetPathLeavesResourceOnFailure(t *testing.T) {
+	user := User{UserName: "bjensen"}
+	user.Schemas = []string{UserURN}
+	err := SetPath(&user, filter.MustParsePath("userName"), map[string]interface{}{"value": "x"})
+	assert.IsType(t, CodecError{}, err)
+	assert.Equal(t, "bjensen", user.UserName)
+	assert.Equal(t, []string{UserURN}, user.Schemas)
+}
@@ -23,6 +23,21 @@ func (er ErrorResponse) Error() string {
 	return fmt.Sprintf("HTTP status: %v, Type: %v, Detail: %v", er.Status, er.ScimType, er.Detail)
 }
 
+//ScimType values returned in an ErrorResponse's ScimType field.
+//https://tools.ietf.org/html/rfc7644#section-3.12
+const (
+	ScimTypeInvalidFilter = "invalidFilter"
+	ScimTypeTooMany       = "tooMany"
+	ScimTypeUniqueness    = "uniqueness"
+	ScimTypeMutability    = "mutability"
+	ScimTypeInvalidSyntax = "invalidSyntax"
+	ScimTypeInvalidPath   = "invalidPath"
+	ScimTypeNoTarget      = "noTarget"
+	ScimTypeInvalidValue  = "invalidValue"
+	ScimTypeInvalidVers   = "invalidVers"
+	ScimTypeSensitive     = "sensitive"
+)
+
 const ListResponseURN = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
 
 //ListResponse defines the SCIM standard response to a valid search query
@@ -34,6 +49,8 @@ type ListResponse struct {
 	Resources    []Resource `json:"Resources"`    //Resources is a multi-valued list of complex objects containing the requested resources.  This MAY be a subset of the full set of resources if pagination (Section 3.4.2.4) is requested.
 	StartIndex   int        `json:"startIndex"`   //StartIndex is the 1-based index of the first result in the current set of list results.  REQUIRED when partial results are returned due to pagination.
 	TotalResults int        `json:"totalResults"` //TotalResults is the total number of results returned by the list or query operation.  The value may be larger than the number of resources returned, such as when returning a single page (see Section 3.4.2.4) of results where multiple pages are available.
+
+	resourceType string //resourceType is the name of the ResourceType whose registered Go type the resources are unmarshaled as.  If it's empty (or unregistered), each resource's meta.resourceType is used instead.
 }
 
 type listResponse struct {
@@ -44,6 +61,40 @@ type listResponse struct {
 	TotalResults int               `json:"totalResults"` //TotalResults is the total number of results returned by the list or query operation.  The value may be larger than the number of resources returned, such as when returning a single page (see Section 3.4.2.4) of results where multiple pages are available.
 }
 
+const PatchOpURN = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+
+//PatchOp is the SCIM standard JSON request body used to modify a subset
+//of a resource's attributes.
+//https://tools.ietf.org/html/rfc7644#section-3.5.2
+type PatchOp struct {
+	Schemas    []string         `json:"schemas"`    //Schemas identifies the request as a PatchOp.
+	Operations []PatchOperation `json:"Operations"` //Operations is the list of changes to be applied in order.
+}
+
+//PatchOperation is a single add, remove or replace operation within a
+//PatchOp.
+type PatchOperation struct {
+	Op    PatchOpType `json:"op"`              //Op is the operation to be performed.
+	Path  string      `json:"path,omitempty"`  //Path is an attribute path describing the target of the operation.  Path is optional for add and replace operations.
+	Value interface{} `json:"value,omitempty"` //Value is the value to be added or replaced.
+}
+
+type PatchOpType string
+
+const (
+	PatchAdd     PatchOpType = "add"
+	PatchRemove  PatchOpType = "remove"
+	PatchReplace PatchOpType = "replace"
+)
+
+//NewPatchOp returns a PatchOp containing the provided operations.
+func NewPatchOp(ops ...PatchOperation) PatchOp {
+	return PatchOp{
+		Schemas:    []string{PatchOpURN},
+		Operations: ops,
+	}
+}
+
 const SearchRequestURN = "urn:ietf:params:scim:api:messages:2.0:SearchRequest"
 
 type SearchRequest struct {
@@ -93,14 +144,21 @@ func (lro *ListResponse) UnmarshalJSON(data []byte) error {
 		log.Debug("CA schemas: ", ca.Schemas)
 		log.Debug("CA meta: ", ca.Meta.ResourceType)
 
-		// TODO: Replace this block with code to unmarshall by resource type.
-		var user User
-		err = json.Unmarshal(rm, &user)
+		// Resources with an unknown (or missing) resource type are
+		// unmarshaled as Users.
+		res, ok := GetResourceRegistry().New(lro.resourceType)
+		if !ok {
+			res, ok = GetResourceRegistry().New(ca.Meta.ResourceType)
+		}
+		if !ok {
+			res = &User{}
+		}
+		err = json.Unmarshal(rm, res)
 		if err != nil {
 			return err
 		}
-		log.Debug("CA as user: ", user)
-		lro.Resources = append(lro.Resources, &user)
+		log.Debug("CA as resource: ", res)
+		lro.Resources = append(lro.Resources, res)
 	}
 
 	log.Trace("(ListResponse) UnmarshalJSON([]byte) error ->")
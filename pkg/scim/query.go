@@ -1,6 +1,13 @@
 package scim
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PennState/scim-client/pkg/scim/filter"
+	log "github.com/sirupsen/logrus"
+)
 
 // NewSearchRequestFromFormat returns a search request with the Filter
 // field constructed from the provided format string and arguments.
@@ -9,3 +16,213 @@ func NewSearchRequestFromFormat(format string, a ...interface{}) SearchRequest {
 		Filter: fmt.Sprintf(format, a...),
 	}
 }
+
+//
+// Query options
+//
+
+// Alphabets used to partition queries by the leading characters of an
+// attribute's value.
+const (
+	HexAlphabet          = "0123456789abcdef"
+	AlphanumericAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+const defaultMaxPartitionDepth = 4
+
+type queryCfg struct {
+	partitionAttr     string
+	partitionAlphabet string
+	maxPartitionDepth int
+	validate          bool
+}
+
+// QueryOpt changes the behavior of the Client's query methods.
+type QueryOpt func(*queryCfg)
+
+// PartitionOnTooMany causes a query that the SCIM server rejects as
+// matching too many resources (scimType "tooMany") to be split into
+// partitions by ranges of the named attribute's value.  Partition
+// boundaries are built from the characters of the alphabet, which should
+// be ordered and match the case the server uses when comparing values -
+// e.g. PartitionOnTooMany("id", HexAlphabet) for UUID identifiers or
+// PartitionOnTooMany("userName", AlphanumericAlphabet).  Partitions that
+// still match too many resources are split again until they fit, and the
+// resources from every partition (and every page of every partition) are
+// merged into a single ListResponse.  Since results are merged in
+// partition order, SortBy is only honored within each partition.
+func PartitionOnTooMany(attr string, alphabet string) QueryOpt {
+	return func(cfg *queryCfg) {
+		cfg.partitionAttr = attr
+		cfg.partitionAlphabet = alphabet
+	}
+}
+
+// MaxPartitionDepth limits the number of times PartitionOnTooMany splits a
+// partition (and therefore the length of the boundary prefixes).
+func MaxPartitionDepth(depth int) QueryOpt {
+	return func(cfg *queryCfg) {
+		cfg.maxPartitionDepth = depth
+	}
+}
+
+// ValidateQuery causes the SearchRequest's filter and sortBy attribute to
+// be checked against the SCIM server's schemas (see
+// ValidateSearchRequest) before the query is sent.  The schemas are
+// retrieved with GetSchemas the first time and reused by the client
+// afterwards.  Since queries of the server root span every resource type,
+// their filters are only checked for syntax errors.
+func ValidateQuery() QueryOpt {
+	return func(cfg *queryCfg) {
+		cfg.validate = true
+	}
+}
+
+//
+// Query partitioning
+//
+
+// partition is the range of an attribute's values that are greater than
+// or equal to lo and less than hi - an empty bound is unbounded.  The
+// prefix is the leading string shared by the values in the range which is
+// extended by the alphabet when the partition is split.
+type partition struct {
+	lo     string
+	hi     string
+	prefix string
+	depth  int
+}
+
+func (p partition) split(alphabet string) []partition {
+	bounds := []string{}
+	for _, r := range alphabet {
+		b := p.prefix + string(r)
+		if (p.lo == "" || b > p.lo) && (p.hi == "" || b < p.hi) {
+			bounds = append(bounds, b)
+		}
+	}
+	if len(bounds) == 0 {
+		return nil
+	}
+
+	children := make([]partition, 0, len(bounds)+1)
+	lo, prefix := p.lo, p.prefix
+	for _, b := range bounds {
+		children = append(children, partition{lo: lo, hi: b, prefix: prefix, depth: p.depth + 1})
+		lo, prefix = b, b
+	}
+	return append(children, partition{lo: lo, hi: p.hi, prefix: prefix, depth: p.depth + 1})
+}
+
+func (p partition) filter(orig string, attr string) string {
+	clauses := []string{}
+	if orig != "" {
+		clauses = append(clauses, "("+orig+")")
+	}
+	if p.lo != "" {
+		clauses = append(clauses, attr+" ge "+filter.FormatValue(p.lo))
+	}
+	if p.hi != "" {
+		clauses = append(clauses, attr+" lt "+filter.FormatValue(p.hi))
+	}
+	return strings.Join(clauses, " and ")
+}
+
+func isTooMany(err error) bool {
+	er, ok := err.(ErrorResponse)
+	return ok && er.ScimType == ScimTypeTooMany
+}
+
+// partitionedQuery performs the query of the ResourceType's endpoint (or,
+// if rt is nil, of the server root).
+func (c Client) partitionedQuery(ctx context.Context, rt *ResourceType, endpoint string, sr SearchRequest, opts ...QueryOpt) (ListResponse, error) {
+	cfg := queryCfg{
+		maxPartitionDepth: defaultMaxPartitionDepth,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var err error
+	if cfg.validate {
+		err = c.validateQuery(ctx, rt, sr)
+		if err != nil {
+			return ListResponse{}, err
+		}
+	}
+
+	if rt != nil {
+		sr.Attributes, err = c.requestAttributes(ctx, *rt, sr.Attributes)
+		if err != nil {
+			return ListResponse{}, err
+		}
+	}
+
+	lr, err := c.query(ctx, endpoint, sr, "")
+	if cfg.partitionAttr == "" || !isTooMany(err) {
+		return lr, err
+	}
+
+	log.Debugf("Partitioning query by %s: %s", cfg.partitionAttr, sr.Filter)
+	merged := ListResponse{
+		Schemas:    []string{ListResponseURN},
+		StartIndex: 1,
+	}
+	sr.StartIndex = 0
+	err = c.queryPartition(ctx, endpoint, sr, cfg, partition{}, &merged, err)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	merged.ItemsPerPage = len(merged.Resources)
+	merged.TotalResults = len(merged.Resources)
+	return merged, nil
+}
+
+// queryPartition splits the partition p and queries each of its children,
+// recursively splitting children that still match too many resources.
+// The cause is the tooMany error returned for the partition p and is
+// returned if the partition can't be split any further.
+func (c Client) queryPartition(
+	ctx context.Context,
+	endpoint string,
+	sr SearchRequest,
+	cfg queryCfg,
+	p partition,
+	merged *ListResponse,
+	cause error) error {
+	children := p.split(cfg.partitionAlphabet)
+	if len(children) == 0 || p.depth >= cfg.maxPartitionDepth {
+		return cause
+	}
+
+	for _, child := range children {
+		psr := sr
+		psr.Filter = child.filter(sr.Filter, cfg.partitionAttr)
+		err := c.queryPages(ctx, endpoint, psr, merged)
+		if isTooMany(err) {
+			err = c.queryPartition(ctx, endpoint, sr, cfg, child, merged, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryPages appends the resources from every page of the query's
+// results to the merged ListResponse (which determines the Go type of the
+// resources - see ListResponse).
+func (c Client) queryPages(ctx context.Context, endpoint string, sr SearchRequest, merged *ListResponse) error {
+	count := 0
+	for {
+		lr, err := c.query(ctx, endpoint, sr, merged.resourceType)
+		if err != nil {
+			return err
+		}
+		merged.Resources = append(merged.Resources, lr.Resources...)
+		count += len(lr.Resources)
+		if len(lr.Resources) == 0 || count >= lr.TotalResults {
+			return nil
+		}
+		sr.StartIndex = count + 1
+	}
+}
@@ -0,0 +1,104 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionSplit(t *testing.T) {
+	root := partition{}
+	children := root.split("abc")
+	assert.Equal(t, []partition{
+		{lo: "", hi: "a", prefix: "", depth: 1},
+		{lo: "a", hi: "b", prefix: "a", depth: 1},
+		{lo: "b", hi: "c", prefix: "b", depth: 1},
+		{lo: "c", hi: "", prefix: "c", depth: 1},
+	}, children)
+
+	assert.Nil(t, children[0].split("abc"))
+	assert.Equal(t, []partition{
+		{lo: "b", hi: "ba", prefix: "b", depth: 2},
+		{lo: "ba", hi: "bb", prefix: "ba", depth: 2},
+		{lo: "bb", hi: "bc", prefix: "bb", depth: 2},
+		{lo: "bc", hi: "c", prefix: "bc", depth: 2},
+	}, children[2].split("abc"))
+}
+
+func TestPartitionFilter(t *testing.T) {
+	p := partition{lo: "a", hi: "b\""}
+	assert.Equal(t, `(active eq true) and userName ge "a" and userName lt "b\""`, p.filter("active eq true", "userName"))
+	assert.Equal(t, "", partition{}.filter("", "userName"))
+}
+
+func TestPartitionOnTooMany(t *testing.T) {
+	const (
+		maxResults = 3
+		tooMany    = `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"scimType":"tooMany","status":"400"}`
+		user       = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"%s","userName":"%s"}`
+	)
+	userNames := []string{"aaron", "abby", "adam", "alice", "bob", "carol", "dave", "zed", "0day"}
+	ge := regexp.MustCompile(`userName ge "([^"]*)"`)
+	lt := regexp.MustCompile(`userName lt "([^"]*)"`)
+
+	queries := 0
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			queries++
+			body, _ := ioutil.ReadAll(req.Body)
+			sr := SearchRequest{}
+			require.NoError(t, json.Unmarshal(body, &sr))
+
+			matches := []string{}
+			for _, un := range userNames {
+				if m := ge.FindStringSubmatch(sr.Filter); m != nil && un < m[1] {
+					continue
+				}
+				if m := lt.FindStringSubmatch(sr.Filter); m != nil && un >= m[1] {
+					continue
+				}
+				matches = append(matches, un)
+			}
+			if len(matches) > maxResults {
+				return jsonResponse(400, tooMany), nil
+			}
+
+			resources := []string{}
+			for _, un := range matches {
+				resources = append(resources, fmt.Sprintf(user, un, un))
+			}
+			return jsonResponse(200, fmt.Sprintf(`{"totalResults":%d,"Resources":[%s]}`, len(matches), strings.Join(resources, ","))), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+
+	sr := SearchRequest{Filter: "active eq true"}
+	_, err = c.QueryResourceType(context.Background(), UserResourceType, sr)
+	assert.True(t, isTooMany(err))
+
+	_, err = c.QueryResourceType(context.Background(), UserResourceType, sr, PartitionOnTooMany("userName", AlphanumericAlphabet), MaxPartitionDepth(1))
+	assert.True(t, isTooMany(err))
+
+	queries = 0
+	lr, err := c.QueryResourceType(context.Background(), UserResourceType, sr, PartitionOnTooMany("userName", AlphanumericAlphabet))
+	require.NoError(t, err)
+	assert.Equal(t, len(userNames), lr.TotalResults)
+	act := []string{}
+	for _, res := range lr.Resources {
+		act = append(act, res.getID())
+	}
+	exp := append([]string{}, userNames...)
+	sort.Strings(exp)
+	assert.Equal(t, exp, act)
+	assert.Equal(t, 1+37+37, queries)
+}
@@ -0,0 +1,118 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+//
+// Error messages
+//
+
+const (
+	emptyReferenceMessage   = "reference is empty"
+	foreignReferenceMessage = "reference does not identify a resource on the SCIM server"
+	unknownEndpointMessage  = "reference does not identify a registered ResourceType endpoint"
+	dotSegmentIDMessage     = "reference's id must not be a dot-segment"
+)
+
+// Dereference retrieves the resource identified by a SCIM reference such
+// as GroupRef.Reference, MemberRef.Reference, Manager.Reference or
+// Meta.Location.  Relative references (e.g. "../Users/2819c223") are
+// resolved against the client's ServiceURL and references to resources
+// that aren't hosted by the SCIM server are refused.  The Go type of the
+// returned resource is chosen from the ResourceRegistry using the
+// endpoint in the reference.
+// https://tools.ietf.org/html/rfc7643#section-2.3.7
+func (c Client) Dereference(ctx context.Context, ref string) (Resource, error) {
+	rt, id, err := c.resolveReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := GetResourceRegistry().New(rt.Name)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", noRegisteredTypeMessage, rt.Name)
+	}
+	return res, c.RetrieveResource(ctx, res, url.PathEscape(id))
+}
+
+// resolveReference returns the ResourceType and (unescaped) id of the
+// resource identified by the provided reference.
+func (c Client) resolveReference(ctx context.Context, ref string) (ResourceType, string, error) {
+	if strings.TrimSpace(ref) == "" {
+		return ResourceType{}, "", errors.New(emptyReferenceMessage)
+	}
+	serviceURL, err := c.serviceURL(ctx)
+	if err != nil {
+		return ResourceType{}, "", err
+	}
+	base, err := url.Parse(serviceURL + "/")
+	if err != nil {
+		return ResourceType{}, "", err
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ResourceType{}, "", err
+	}
+
+	// Relative references are relative to the service provider base URI
+	// regardless of any leading dot-segments.  Absolute paths must be
+	// within the base URI's path.
+	if !u.IsAbs() && u.Host == "" {
+		rel := *u
+		if !strings.HasPrefix(rel.Path, "/") {
+			rel.Path = trimDotSegments(rel.Path)
+			rel.RawPath = trimDotSegments(u.EscapedPath())
+		}
+		u = base.ResolveReference(&rel)
+	}
+
+	if !strings.EqualFold(u.Scheme, base.Scheme) || normalizedHost(u) != normalizedHost(base) || !strings.HasPrefix(u.Path, base.Path) {
+		return ResourceType{}, "", fmt.Errorf("%s: %s", foreignReferenceMessage, ref)
+	}
+
+	path := strings.Trim(strings.TrimPrefix(u.Path, base.Path), "/")
+	segments := strings.Split(path, "/")
+	if len(segments) != 2 || segments[1] == "" {
+		return ResourceType{}, "", fmt.Errorf("%s: %s", unknownEndpointMessage, ref)
+	}
+	if segments[1] == "." || segments[1] == ".." {
+		return ResourceType{}, "", fmt.Errorf("%s: %s", dotSegmentIDMessage, ref)
+	}
+	rt, ok := GetResourceRegistry().LookupByEndpoint("/" + segments[0])
+	if !ok {
+		return ResourceType{}, "", fmt.Errorf("%s: %s", unknownEndpointMessage, ref)
+	}
+	return rt, segments[1], nil
+}
+
+// normalizedHost returns the URL's lower-cased host without the scheme's
+// default port so that https://example.com and https://example.com:443
+// compare equal.
+func normalizedHost(u *url.URL) string {
+	host := strings.ToLower(u.Host)
+	switch port := u.Port(); {
+	case port == "443" && strings.EqualFold(u.Scheme, "https"),
+		port == "80" && strings.EqualFold(u.Scheme, "http"):
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	return host
+}
+
+// trimDotSegments removes the leading "./" and "../" segments from a
+// relative path.
+func trimDotSegments(path string) string {
+	for {
+		switch {
+		case strings.HasPrefix(path, "./"):
+			path = path[2:]
+		case strings.HasPrefix(path, "../"):
+			path = path[3:]
+		default:
+			return path
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDereference(t *testing.T) {
+	const (
+		user  = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"%s","userName":"bjensen"}`
+		group = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:Group"],"id":"%s","displayName":"Tour Guides"}`
+	)
+
+	var path string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			path = req.URL.EscapedPath()
+			id := path[strings.LastIndex(path, "/")+1:]
+			if strings.Contains(path, "/Groups/") {
+				return jsonResponse(200, fmt.Sprintf(group, id)), nil
+			}
+			return jsonResponse(200, fmt.Sprintf(user, id)), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/v2")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		ref  string
+		path string
+		typ  Resource
+		err  string
+	}{
+		{"Absolute user", "https://example.com/v2/Users/2819c223", "/v2/Users/2819c223", &User{}, ""},
+		{"Absolute group", "https://EXAMPLE.com/v2/Groups/e9e30dba", "/v2/Groups/e9e30dba", &Group{}, ""},
+		{"Relative with dot-segments", "../Users/2819c223", "/v2/Users/2819c223", &User{}, ""},
+		{"Relative", "Groups/e9e30dba", "/v2/Groups/e9e30dba", &Group{}, ""},
+		{"Absolute path", "/v2/Users/2819c223", "/v2/Users/2819c223", &User{}, ""},
+		{"Empty", "", "", nil, emptyReferenceMessage},
+		{"Foreign host", "https://evil.example.org/v2/Users/2819c223", "", nil, foreignReferenceMessage + ": https://evil.example.org/v2/Users/2819c223"},
+		{"Default port", "https://example.com:443/v2/Users/2819c223", "/v2/Users/2819c223", &User{}, ""},
+		{"Other port", "https://example.com:8443/v2/Users/2819c223", "", nil, foreignReferenceMessage + ": https://example.com:8443/v2/Users/2819c223"},
+		{"Foreign scheme", "http://example.com/v2/Users/2819c223", "", nil, foreignReferenceMessage + ": http://example.com/v2/Users/2819c223"},
+		{"Outside base path", "https://example.com/v1/Users/2819c223", "", nil, foreignReferenceMessage + ": https://example.com/v1/Users/2819c223"},
+		{"Unknown endpoint", "../Widgets/2819c223", "", nil, unknownEndpointMessage + ": ../Widgets/2819c223"},
+		{"Missing id", "../Users/", "", nil, unknownEndpointMessage + ": ../Users/"},
+		{"Absolute path outside base path", "/v1/Users/2819c223", "", nil, foreignReferenceMessage + ": /v1/Users/2819c223"},
+		{"Relative with leading dots in id", "./Users/..2819c223", "/v2/Users/..2819c223", &User{}, ""},
+		{"Escaped query in id", "../Users/2819c223%3Fattributes=password", "/v2/Users/2819c223%3Fattributes=password", &User{}, ""},
+		{"Dot-segment id", "https://example.com/v2/Users/..", "", nil, dotSegmentIDMessage + ": https://example.com/v2/Users/.."},
+		{"Escaped dot-segment id", "../Users/%2E%2E", "", nil, dotSegmentIDMessage + ": ../Users/%2E%2E"},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			path = ""
+			res, err := c.Dereference(context.Background(), test.ref)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				assert.Equal(t, "", path)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, test.typ, res)
+			assert.Equal(t, test.path, path)
+			assert.Equal(t, test.path[strings.LastIndex(test.path, "/")+1:], res.getID())
+		})
+	}
+}
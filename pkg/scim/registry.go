@@ -1,10 +1,14 @@
 package scim
 
-import "sync"
+import (
+	"reflect"
+	"sync"
+)
 
 //ResourceRegistry contains a map which will be treated as a singleton.
 type ResourceRegistry struct {
 	resourceMap map[string]ResourceType
+	typeMap     map[string]reflect.Type
 }
 
 var instance *ResourceRegistry
@@ -18,13 +22,14 @@ func GetResourceRegistry() *ResourceRegistry {
 	once.Do(func() {
 		instance = &ResourceRegistry{
 			resourceMap: make(map[string]ResourceType),
+			typeMap:     make(map[string]reflect.Type),
 		}
-		instance.Register(
-			GroupResourceType,
-			ResourceTypeResourceType,
-			SchemaResourceType,
-			ServiceProviderConfigResourceType,
-			UserResourceType,
+		instance.RegisterResource(
+			&Group{},
+			&ResourceType{},
+			&Schema{},
+			&ServiceProviderConfig{},
+			&User{},
 		)
 	})
 	return instance
@@ -45,3 +50,38 @@ func (rr ResourceRegistry) Register(rts ...ResourceType) {
 		rr.resourceMap[rt.Name] = rt
 	}
 }
+
+//RegisterResource adds the ResourceType of each of the provided resources
+//to the registry along with the resource's Go type so that New can create
+//instances of it.  The resources must be pointers to the Go type - e.g.
+//&User{}.
+func (rr ResourceRegistry) RegisterResource(res ...Resource) {
+	for _, r := range res {
+		rt := r.ResourceType()
+		rr.Register(rt)
+		rr.typeMap[rt.Name] = reflect.TypeOf(r).Elem()
+	}
+}
+
+//New returns a pointer to a new, empty instance of the Go type registered
+//for the named ResourceType as well as a boolean that indicates whether
+//the registry contained a Go type for the ResourceType.
+func (rr ResourceRegistry) New(name string) (Resource, bool) {
+	typ, ok := rr.typeMap[name]
+	if !ok {
+		return nil, false
+	}
+	return reflect.New(typ).Interface().(Resource), true
+}
+
+//LookupByEndpoint returns the ResourceType with the provided endpoint
+//(e.g. "/Users") as well as a boolean that indicates whether the registry
+//contained a matching ResourceType.
+func (rr ResourceRegistry) LookupByEndpoint(endpoint string) (ResourceType, bool) {
+	for _, rt := range rr.resourceMap {
+		if rt.Endpoint == endpoint {
+			return rt, true
+		}
+	}
+	return ResourceType{}, false
+}
@@ -44,3 +44,13 @@ func TestResourceTypeCanBeAdded(t *testing.T) {
 	assert.True(ok)
 	assert.Equal(myResourceType, rt)
 }
+
+func TestRegistryNewCreatesRegisteredType(t *testing.T) {
+	assert := assert.New(t)
+	registry := GetResourceRegistry()
+	res, ok := registry.New("Group")
+	assert.True(ok)
+	assert.IsType(&Group{}, res)
+	_, ok = registry.New("Missing")
+	assert.False(ok)
+}
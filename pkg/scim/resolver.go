@@ -0,0 +1,205 @@
+package scim
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// MembershipResolver expands nested Groups into their effective
+// membership.  Groups are retrieved from the SCIM server at most once per
+// MembershipResolver so a resolver should be used for a single run (e.g.
+// one synchronization job) and then discarded.
+type MembershipResolver struct {
+	client  *Client
+	mu      sync.Mutex
+	groups  map[string]*Group
+	members map[string]map[string]bool
+	cycles  map[string][]string
+}
+
+// NewMembershipResolver returns a MembershipResolver that retrieves Groups
+// using the provided Client.
+func NewMembershipResolver(client *Client) *MembershipResolver {
+	return &MembershipResolver{
+		client:  client,
+		groups:  map[string]*Group{},
+		members: map[string]map[string]bool{},
+		cycles:  map[string][]string{},
+	}
+}
+
+// MembershipGraph describes the direct membership of a Group and of every
+// Group nested within it.
+type MembershipGraph struct {
+	Users  map[string][]string //Users maps a group's id to the ids of its direct User members.
+	Groups map[string][]string //Groups maps a group's id to the ids of its direct Group members.
+	Cycles [][]string          //Cycles lists the group ids forming each membership cycle - the first id is repeated at the end.
+}
+
+// Graph returns the MembershipGraph of the Group with the provided id.
+func (mr *MembershipResolver) Graph(ctx context.Context, groupID string) (MembershipGraph, error) {
+	mg := MembershipGraph{
+		Users:  map[string][]string{},
+		Groups: map[string][]string{},
+	}
+	err := mr.walk(ctx, groupID, []string{}, map[string]bool{}, &mg)
+	return mg, err
+}
+
+// Members returns the ids of the Users that are direct or indirect
+// members of the Group with the provided id.
+func (mr *MembershipResolver) Members(ctx context.Context, groupID string) ([]string, error) {
+	members, err := mr.effectiveMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(members))
+	for id := range members {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// IsMember indicates whether the User with the provided id is a direct or
+// indirect member of the Group with the provided id.
+func (mr *MembershipResolver) IsMember(ctx context.Context, userID string, groupID string) (bool, error) {
+	members, err := mr.effectiveMembers(ctx, groupID)
+	if err != nil {
+		return false, err
+	}
+	return members[userID], nil
+}
+
+// Cycles returns the membership cycles detected by the resolver so far.
+func (mr *MembershipResolver) Cycles() [][]string {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	cycles := make([][]string, 0, len(mr.cycles))
+	for _, cycle := range mr.cycles {
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+func (mr *MembershipResolver) effectiveMembers(ctx context.Context, groupID string) (map[string]bool, error) {
+	mr.mu.Lock()
+	members, ok := mr.members[groupID]
+	mr.mu.Unlock()
+	if ok {
+		return members, nil
+	}
+
+	mg, err := mr.Graph(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members = map[string]bool{}
+	for _, users := range mg.Users {
+		for _, id := range users {
+			members[id] = true
+		}
+	}
+
+	mr.mu.Lock()
+	mr.members[groupID] = members
+	mr.mu.Unlock()
+	return members, nil
+}
+
+// walk performs a depth-first traversal of the nested groups below the
+// Group with the provided id, recording each group's direct members and
+// any cycles (groups that are encountered while they're on the path from
+// the root).
+func (mr *MembershipResolver) walk(ctx context.Context, groupID string, path []string, visited map[string]bool, mg *MembershipGraph) error {
+	for i, id := range path {
+		if id == groupID {
+			cycle := append(append([]string{}, path[i:]...), groupID)
+			mg.Cycles = append(mg.Cycles, cycle)
+			key := cycleKey(cycle)
+			mr.mu.Lock()
+			if _, ok := mr.cycles[key]; !ok {
+				mr.cycles[key] = cycle
+			}
+			mr.mu.Unlock()
+			return nil
+		}
+	}
+	if visited[groupID] {
+		return nil
+	}
+	visited[groupID] = true
+
+	g, err := mr.group(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	users := []string{}
+	groups := []string{}
+	for _, m := range g.Members {
+		if mr.isGroup(ctx, m) {
+			groups = append(groups, m.Value)
+			continue
+		}
+		users = append(users, m.Value)
+	}
+	mg.Users[groupID] = users
+	mg.Groups[groupID] = groups
+
+	path = append(path, groupID)
+	for _, id := range groups {
+		err := mr.walk(ctx, id, path, visited, mg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// group returns the (cached) Group with the provided id.
+func (mr *MembershipResolver) group(ctx context.Context, groupID string) (*Group, error) {
+	mr.mu.Lock()
+	g, ok := mr.groups[groupID]
+	mr.mu.Unlock()
+	if ok {
+		return g, nil
+	}
+
+	g = &Group{}
+	err := mr.client.RetrieveResource(ctx, g, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	mr.groups[groupID] = g
+	mr.mu.Unlock()
+	return g, nil
+}
+
+// isGroup indicates whether a member is a Group using the member's type
+// or, if the type is missing, the endpoint in the member's reference.
+func (mr *MembershipResolver) isGroup(ctx context.Context, m MemberRef) bool {
+	if m.Type != "" {
+		return strings.EqualFold(m.Type, GroupResourceType.Name)
+	}
+	if m.Reference == "" {
+		return false
+	}
+	rt, _, err := mr.client.resolveReference(ctx, m.Reference)
+	return err == nil && rt.Name == GroupResourceType.Name
+}
+
+// cycleKey returns the same key for every rotation of a cycle so that a
+// cycle entered from different groups is only recorded once.
+func cycleKey(cycle []string) string {
+	ids := cycle[:len(cycle)-1]
+	start := 0
+	for i, id := range ids {
+		if id < ids[start] {
+			start = i
+		}
+	}
+	return strings.Join(append(append([]string{}, ids[start:]...), ids[:start]...), "/")
+}
@@ -0,0 +1,83 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembershipResolver(t *testing.T) {
+	groups := map[string][]MemberRef{
+		"staff": {
+			{Value: "alice"},
+			{Multivalued: Multivalued{Type: "Group"}, Value: "guides"},
+			{Multivalued: Multivalued{Reference: "../Groups/drivers"}, Value: "drivers"},
+		},
+		"guides": {
+			{Multivalued: Multivalued{Type: "User"}, Value: "bob"},
+			{Multivalued: Multivalued{Reference: "https://example.com/scim/Users/carol"}, Value: "carol"},
+			{Multivalued: Multivalued{Type: "Group"}, Value: "seniors"},
+		},
+		"seniors": {
+			{Value: "dave"},
+			{Multivalued: Multivalued{Type: "Group"}, Value: "guides"},
+		},
+		"drivers": {
+			{Value: "erin"},
+		},
+	}
+
+	retrievals := map[string]int{}
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+			retrievals[id]++
+			members, ok := groups[id]
+			if !ok {
+				return jsonResponse(404, `{"status":"404"}`), nil
+			}
+			g, err := json.Marshal(map[string]interface{}{"id": id, "members": members})
+			require.NoError(t, err)
+			return jsonResponse(200, string(g)), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+	mr := NewMembershipResolver(c)
+	ctx := context.Background()
+
+	members, err := mr.Members(ctx, "staff")
+	require.NoError(t, err)
+	sort.Strings(members)
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave", "erin"}, members)
+
+	ok, err := mr.IsMember(ctx, "dave", "staff")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = mr.IsMember(ctx, "alice", "guides")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = mr.IsMember(ctx, "bob", "seniors")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	for id, count := range retrievals {
+		assert.Equal(t, 1, count, id)
+	}
+
+	mg, err := mr.Graph(ctx, "staff")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"guides", "drivers"}, mg.Groups["staff"])
+	assert.Equal(t, []string{"bob", "carol"}, mg.Users["guides"])
+	assert.Equal(t, [][]string{{"guides", "seniors", "guides"}}, mg.Cycles)
+	assert.Equal(t, [][]string{{"guides", "seniors", "guides"}}, mr.Cycles())
+
+	_, err = mr.Members(ctx, "missing")
+	assert.Error(t, err)
+}
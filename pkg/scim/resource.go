@@ -25,8 +25,10 @@ type ServerDiscoveryResource interface {
 type resource interface {
 	addAdditionalProperties(additionalProperties map[string]json.RawMessage)
 	getAdditionalProperties() map[string]json.RawMessage
+	getExternalID() string
 	getID() string
 	getMeta() *Meta
+	setID(id string)
 }
 
 //Resource identifies the implementing code as a SCIM resource.  Resources
@@ -89,14 +91,22 @@ func (ca CommonAttributes) getAdditionalProperties() map[string]json.RawMessage
 	return ca.AdditionalProperties
 }
 
+func (ca CommonAttributes) getExternalID() string {
+	return ca.ExternalID
+}
+
 func (ca CommonAttributes) getID() string {
 	return ca.ID
 }
 
-func (ca CommonAttributes) getMeta() *Meta {
+func (ca *CommonAttributes) getMeta() *Meta {
 	return &ca.Meta
 }
 
+func (ca *CommonAttributes) setID(id string) {
+	ca.ID = id
+}
+
 //AddExtension adds a new SCIM extension to a SCIM resource.  This method is
 //purposely designed to return an error if the provided extension's URN is
 //already a key in the additionalProperties map.
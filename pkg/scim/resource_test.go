@@ -246,3 +246,19 @@ func TestBadResourceUnmarshaling(t *testing.T) {
 	err := json.Unmarshal([]byte(badResourceJSON), &ca)
 	assert.NotNil(err)
 }
+
+func TestUserAndGroupDecodersAreIndependent(t *testing.T) {
+	var user User
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"2819c223","userName":"bjensen"}`), &user))
+
+	var group Group
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"e9e30dba","displayName":"Tour Guides","members":[{"value":"a"},{"value":"b"}]}`), &group))
+	assert.Equal(t, "Tour Guides", group.DisplayName)
+	assert.Equal(t, []MemberRef{{Value: "a"}, {Value: "b"}}, group.Members)
+}
+
+func TestResourceTypeSchemas(t *testing.T) {
+	assert.Equal(t, UserURN, UserResourceType.Schema)
+	assert.Equal(t, []SchemaExtension{{Schema: EnterpriseUserURN}}, UserResourceType.SchemaExtensions)
+	assert.Equal(t, GroupURN, GroupResourceType.Schema)
+}
@@ -0,0 +1,174 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/PennState/httputil/pkg/httperror"
+	"github.com/PennState/scim-client/pkg/scim/filter"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultMaxFilterIDs is the number of ids combined into a single filter
+// when the SCIM server's FilterConfig.MaxResults is unknown.
+const defaultMaxFilterIDs = 50
+
+const noRegisteredTypeMessage = "no Go type is registered for ResourceType"
+
+// RetrieveResult contains either the resource retrieved for an id or
+// the error that prevented its retrieval.
+type RetrieveResult struct {
+	Resource Resource
+	Err      error
+}
+
+// RetrieveMany retrieves the resources of the provided ResourceType with
+// the provided ids.  The ids are combined into "id eq" filters, each
+// matching no more than the server's FilterConfig.MaxResults resources.
+// If the server doesn't support filtering, the resources are retrieved
+// individually using up to the client's MaxConcurrency parallel requests
+// - in this case the ResourceType's Go type must be registered with the
+// ResourceRegistry (see RegisterResource).  Resources are returned as the
+// ResourceType's registered Go type (if any) regardless of their
+// meta.resourceType.  The returned map contains a RetrieveResult for each
+// requested id.
+func (c Client) RetrieveMany(ctx context.Context, rt ResourceType, ids []string) (map[string]RetrieveResult, error) {
+	ids = unique(ids)
+	results := make(map[string]RetrieveResult, len(ids))
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	size := defaultMaxFilterIDs
+	spc, err := c.serviceProviderConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if spc != nil && !spc.FilterConfig.Supported {
+		return results, c.retrieveEach(ctx, rt, ids, results)
+	}
+	if spc != nil && spc.FilterConfig.MaxResults > 0 && spc.FilterConfig.MaxResults < size {
+		size = spc.FilterConfig.MaxResults
+	}
+
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[start:end]
+		err := c.retrieveChunk(ctx, rt, chunk, results)
+		if isFilterUnsupported(err) {
+			log.Debug("Filtering by id is not supported - falling back to individual retrieval: ", err)
+			return results, c.retrieveEach(ctx, rt, ids[start:], results)
+		}
+		if err != nil {
+			for _, id := range chunk {
+				results[id] = RetrieveResult{Err: err}
+			}
+		}
+	}
+	return results, nil
+}
+
+// retrieveChunk retrieves the resources with the provided ids using a
+// single filtered query.
+func (c Client) retrieveChunk(ctx context.Context, rt ResourceType, ids []string, results map[string]RetrieveResult) error {
+	byID := filter.Attr("id").Eq(ids[0])
+	for _, id := range ids[1:] {
+		byID = byID.Or(filter.Attr("id").Eq(id))
+	}
+	sr := SearchRequest{
+		Filter: byID.String(),
+		Count:  len(ids),
+	}
+
+	lr := ListResponse{resourceType: rt.Name}
+	err := c.queryPages(ctx, rt.Endpoint+"/.search", sr, &lr)
+	if err != nil {
+		return err
+	}
+	for _, res := range lr.Resources {
+		results[res.getID()] = RetrieveResult{Resource: res}
+	}
+	for _, id := range ids {
+		if _, ok := results[id]; !ok {
+			results[id] = RetrieveResult{Err: notFound(rt, id)}
+		}
+	}
+	return nil
+}
+
+// retrieveEach retrieves the resources with the provided ids using one
+// request per resource.
+func (c Client) retrieveEach(ctx context.Context, rt ResourceType, ids []string, results map[string]RetrieveResult) error {
+	registry := GetResourceRegistry()
+	if _, ok := registry.New(rt.Name); !ok {
+		return fmt.Errorf("%s: %s", noRegisteredTypeMessage, rt.Name)
+	}
+
+	ops := make([]Operation, len(ids))
+	for i, id := range ids {
+		res, _ := registry.New(rt.Name)
+		ops[i] = Operation{
+			Type:     RetrieveOperation,
+			Resource: res,
+			ID:       id,
+		}
+	}
+	for _, result := range c.Execute(ctx, ops) {
+		op := result.Operation
+		if result.Err != nil {
+			results[op.ID] = RetrieveResult{Err: result.Err}
+			continue
+		}
+		results[op.ID] = RetrieveResult{Resource: op.Resource}
+	}
+	return nil
+}
+
+// isFilterUnsupported indicates whether the error was caused by the SCIM
+// server not supporting the filter used to retrieve resources by id.
+func isFilterUnsupported(err error) bool {
+	switch e := err.(type) {
+	case ErrorResponse:
+		status, _ := strconv.Atoi(e.Status)
+		return e.ScimType == ScimTypeInvalidFilter || status == http.StatusNotImplemented
+	case httperror.HTTPError:
+		return e.Code == http.StatusNotImplemented
+	}
+	return false
+}
+
+// notFound returns the error a SCIM server would return when retrieving
+// a resource that doesn't exist.
+func notFound(rt ResourceType, id string) error {
+	return ErrorResponse{
+		Schemas: []string{ErrorResponseURN},
+		Detail:  fmt.Sprintf("%s %s not found", rt.Name, id),
+		Status:  strconv.Itoa(http.StatusNotFound),
+	}
+}
+
+// concurrency returns the number of parallel requests allowed by the
+// provided limit.
+func concurrency(limit int) int {
+	if limit < 1 {
+		return 1
+	}
+	return limit
+}
+
+func unique(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrieveMany(t *testing.T) {
+	const (
+		spc           = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"],"filter":{"supported":%t,"maxResults":2}}`
+		user          = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"%s","userName":"%s","meta":{"resourceType":"User"}}`
+		invalidFilter = `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"scimType":"invalidFilter","status":"400"}`
+	)
+	existing := map[string]bool{"a": true, "b": true, "c": true, "e": true}
+	ids := []string{"a", "b", "c", "d", "e", "a"}
+	idEq := regexp.MustCompile(`id eq "([^"]*)"`)
+
+	tests := []struct {
+		name      string
+		discovery bool
+		filter    bool
+		rejected  bool
+		searches  int
+		gets      int
+	}{
+		{"Filtered without discovery", false, true, false, 1, 0},
+		{"Filtered with maxResults", true, true, false, 3, 0},
+		{"Filter not supported", true, false, false, 0, 5},
+		{"Filter rejected", false, true, true, 1, 5},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			searches, gets := 0, 0
+			hc := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case strings.HasSuffix(req.URL.Path, "/ServiceProviderConfig"):
+						return jsonResponse(200, fmt.Sprintf(spc, test.filter)), nil
+					case strings.HasSuffix(req.URL.Path, "/.search"):
+						searches++
+						if test.rejected {
+							return jsonResponse(400, invalidFilter), nil
+						}
+						body, _ := ioutil.ReadAll(req.Body)
+						sr := SearchRequest{}
+						require.NoError(t, json.Unmarshal(body, &sr))
+						resources := []string{}
+						for _, m := range idEq.FindAllStringSubmatch(sr.Filter, -1) {
+							if existing[m[1]] {
+								resources = append(resources, fmt.Sprintf(user, m[1], m[1]))
+							}
+						}
+						return jsonResponse(200, fmt.Sprintf(`{"totalResults":%d,"Resources":[%s]}`, len(resources), strings.Join(resources, ","))), nil
+					default:
+						gets++
+						id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+						if !existing[id] {
+							return jsonResponse(404, `{"status":"404","detail":"not found"}`), nil
+						}
+						return jsonResponse(200, fmt.Sprintf(user, id, id)), nil
+					}
+				}),
+			}
+			c, err := NewClient(hc, "https://example.com/scim", DisableDiscovery(!test.discovery), MaxConcurrency(2))
+			require.NoError(t, err)
+
+			results, err := c.RetrieveMany(context.Background(), UserResourceType, ids)
+			require.NoError(t, err)
+			assert.Equal(t, test.searches, searches)
+			assert.Equal(t, test.gets, gets)
+			assert.Len(t, results, 5)
+			for id, result := range results {
+				if !existing[id] {
+					assert.Nil(t, result.Resource)
+					assert.Error(t, result.Err)
+					continue
+				}
+				require.NoError(t, result.Err)
+				assert.Equal(t, id, result.Resource.(*User).UserName)
+			}
+		})
+	}
+}
+
+func TestRetrieveManyRequiresRegisteredType(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(501, `{"status":"501"}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim", DisableDiscovery(true))
+	require.NoError(t, err)
+
+	rt := ResourceType{Name: "Unregistered", Endpoint: "/Unregistered"}
+	_, err = c.RetrieveMany(context.Background(), rt, []string{"a"})
+	assert.EqualError(t, err, noRegisteredTypeMessage+": Unregistered")
+}
+
+func TestRetrieveManyGroupsWithoutMeta(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(200, `{"totalResults":2,"Resources":[
+				{"schemas":["urn:ietf:params:scim:schemas:core:2.0:Group"],"id":"e9e30dba","displayName":"Tour Guides","members":[{"value":"2819c223"}]},
+				{"schemas":["urn:ietf:params:scim:schemas:core:2.0:Group"],"id":"fc348aa8","displayName":"Employees"}
+			]}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim", DisableDiscovery(true))
+	require.NoError(t, err)
+
+	results, err := c.RetrieveMany(context.Background(), GroupResourceType, []string{"e9e30dba", "fc348aa8"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.NoError(t, results["e9e30dba"].Err)
+	require.IsType(t, &Group{}, results["e9e30dba"].Resource)
+	group := results["e9e30dba"].Resource.(*Group)
+	assert.Equal(t, "Tour Guides", group.DisplayName)
+	require.Len(t, group.Members, 1)
+	assert.Equal(t, "2819c223", group.Members[0].Value)
+	assert.IsType(t, &Group{}, results["fc348aa8"].Resource)
+}
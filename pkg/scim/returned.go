@@ -0,0 +1,200 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// CheckReturned checks that a resource returned by a SCIM server includes
+// every attribute that the provided schemas define as "returned: always"
+// (see RFC7643 section 7) - such attributes must be returned regardless of
+// the attributes or excludedAttributes parameters but some servers omit
+// them when either is used.  The sub-attributes of the complex values and
+// the attributes of the extensions that the resource includes are checked
+// too.  The id attribute is always checked.  Missing attributes are
+// reported as Diagnostics in an InvalidResourceError.
+func CheckReturned(res Resource, schemas ...Schema) error {
+	doc, err := document(res)
+	if err != nil {
+		return err
+	}
+	v := validator{evaluator: newEvaluator(res, schemas), diags: []Diagnostic{}}
+	v.returned("", doc, v.root(doc).attrs)
+	for _, se := range v.extensions {
+		if ext, ok := lookup(doc, se.Schema).(map[string]interface{}); ok {
+			v.returned(se.Schema+":", ext, v.schemas[strings.ToLower(se.Schema)].Attributes)
+		}
+	}
+	if len(v.diags) > 0 {
+		return InvalidResourceError{ResourceType: res.ResourceType().Name, Diagnostics: v.diags}
+	}
+	return nil
+}
+
+// returned reports the always returned attributes that are missing from
+// the object - prefix is prepended to each attribute's name to form its
+// path.
+func (v *validator) returned(prefix string, obj map[string]interface{}, attrs []Attribute) {
+	for idx := range attrs {
+		attr := &attrs[idx]
+		path := prefix + attr.Name
+		value := lookup(obj, attr.Name)
+		if isEmpty(value) {
+			if attr.Returned == Always {
+				v.report(path, "attribute is always returned but is missing")
+			}
+			continue
+		}
+		if attr.Type != Complex {
+			continue
+		}
+		if !attr.Multivalued {
+			if sub, ok := value.(map[string]interface{}); ok {
+				v.returned(path+".", sub, attr.SubAttributes)
+			}
+			continue
+		}
+		for i, e := range asSlice(value) {
+			if sub, ok := e.(map[string]interface{}); ok {
+				v.returned(path+fmt.Sprintf("[%d].", i), sub, attr.SubAttributes)
+			}
+		}
+	}
+}
+
+// RequestAttributes returns the value of the attributes parameter (see
+// RFC7644 section 3.4.2.5) that retrieves the attributes of the resource's
+// Go type that the provided schemas define as "returned: request" - such
+// attributes are only returned when they're explicitly requested.  The
+// attributes (and sub-attributes) of the core schema that are declared by
+// the Go type's JSON fields are added to the provided attributes.  Since
+// the attributes parameter replaces the attributes that are returned by
+// default, if no attributes are provided, every attribute that the Go type
+// declares (and the resource type's schema extensions) are requested too.
+// The provided attributes are returned unchanged if the Go type doesn't
+// declare any request-only attributes.  Clients created with the
+// IncludeRequestAttributes option do this for every retrieval and query.
+//
+//	sr.Attributes = scim.RequestAttributes(&scim.User{}, sr.Attributes, schemas...)
+func RequestAttributes(res Resource, attributes []string, schemas ...Schema) []string {
+	e := newEvaluator(res, schemas)
+	declared := jsonFields(reflect.TypeOf(res))
+	attrs := e.schemas[strings.ToLower(e.urn)].Attributes
+
+	requested := []string{}
+	for _, attr := range attrs {
+		ft, ok := declared[strings.ToLower(attr.Name)]
+		if !ok {
+			continue
+		}
+		if attr.Returned == Request {
+			requested = append(requested, attr.Name)
+			continue
+		}
+		subs := jsonFields(ft)
+		for _, sub := range attr.SubAttributes {
+			if _, ok := subs[strings.ToLower(sub.Name)]; ok && sub.Returned == Request {
+				requested = append(requested, attr.Name+"."+sub.Name)
+			}
+		}
+	}
+	if len(requested) == 0 {
+		return attributes
+	}
+
+	result := append([]string{}, attributes...)
+	if len(attributes) == 0 {
+		for _, attr := range append(append([]Attribute{}, commonAttributes...), attrs...) {
+			if _, ok := declared[strings.ToLower(attr.Name)]; ok && attr.Returned != Never && attr.Returned != Request {
+				result = append(result, attr.Name)
+			}
+		}
+		for _, se := range e.extensions {
+			result = append(result, se.Schema)
+		}
+	}
+	for _, r := range requested {
+		if !containsFold(result, r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// requestAttributes returns the attributes parameter of a query of the
+// ResourceType with the request-only attributes of its registered Go type
+// added if the client was created with the IncludeRequestAttributes option.
+func (c Client) requestAttributes(ctx context.Context, rt ResourceType, attributes []string) ([]string, error) {
+	if !c.cfg.IncludeRequestAttributes {
+		return attributes, nil
+	}
+	res, ok := GetResourceRegistry().New(rt.Name)
+	if !ok {
+		return attributes, nil
+	}
+	schemas, err := c.schemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return RequestAttributes(res, attributes, schemas...), nil
+}
+
+// requestAttributesQuery returns the query string (including the leading
+// "?") that requests the resource's request-only attributes if the client
+// was created with the IncludeRequestAttributes option.
+func (c Client) requestAttributesQuery(ctx context.Context, res Resource) (string, error) {
+	if !c.cfg.IncludeRequestAttributes {
+		return "", nil
+	}
+	schemas, err := c.schemas(ctx)
+	if err != nil {
+		return "", err
+	}
+	attributes := RequestAttributes(res, nil, schemas...)
+	if len(attributes) == 0 {
+		return "", nil
+	}
+	return "?" + url.Values{"attributes": {strings.Join(attributes, ",")}}.Encode(), nil
+}
+
+// jsonFields returns the types of the JSON fields of the struct (or
+// pointer to, or slice of, struct) type keyed by their lower-cased names.
+// The fields of embedded structs are included.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	fields := map[string]reflect.Type{}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "-" || name == "*":
+		case f.Anonymous && name == "":
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+		case f.PkgPath != "":
+		case name == "":
+			fields[strings.ToLower(f.Name)] = f.Type
+		default:
+			fields[strings.ToLower(name)] = f.Type
+		}
+	}
+	return fields
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
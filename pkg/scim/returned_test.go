@@ -0,0 +1,144 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReturned(t *testing.T) {
+	schemas := []Schema{
+		{
+			CommonAttributes: CommonAttributes{ID: UserURN},
+			Attributes: []Attribute{
+				{Name: "userName", Type: String, Returned: Always},
+				{Name: "nickName", Type: String, Returned: Default},
+				{Name: "emails", Type: Complex, Multivalued: true, SubAttributes: []Attribute{
+					{Name: "value", Type: String, Returned: Always},
+					{Name: "type", Type: String},
+				}},
+			},
+		},
+		{
+			CommonAttributes: CommonAttributes{ID: EnterpriseUserURN},
+			Attributes: []Attribute{
+				{Name: "employeeNumber", Type: String, Returned: Always},
+			},
+		},
+	}
+
+	u := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &u))
+	assert.NoError(t, CheckReturned(&u, schemas...))
+
+	u = User{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"],
+		"emails": [{"value": "bjensen@example.com"}, {"type": "home"}],
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"costCenter": "4130"}
+	}`), &u))
+	err := CheckReturned(&u, schemas...)
+	require.IsType(t, InvalidResourceError{}, err)
+	assert.Equal(t, []Diagnostic{
+		{"id", "attribute is always returned but is missing"},
+		{"userName", "attribute is always returned but is missing"},
+		{"emails[1].value", "attribute is always returned but is missing"},
+		{EnterpriseUserURN + ":employeeNumber", "attribute is always returned but is missing"},
+	}, err.(InvalidResourceError).Diagnostics)
+}
+
+func TestRequestAttributes(t *testing.T) {
+	schemas := []Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "userName", Type: String, Returned: Always},
+			{Name: "title", Type: String, Returned: Request},
+			{Name: "password", Type: String, Returned: Never},
+			{Name: "badge", Type: String, Returned: Request},
+			{Name: "name", Type: Complex, SubAttributes: []Attribute{
+				{Name: "givenName", Type: String},
+				{Name: "middleName", Type: String, Returned: Request},
+			}},
+			{Name: "emails", Type: Complex, Multivalued: true, SubAttributes: []Attribute{
+				{Name: "value", Type: String},
+			}},
+		},
+	}}
+
+	tests := []struct {
+		name       string
+		attributes []string
+		schemas    []Schema
+		exp        []string
+	}{
+		{"Appended", []string{"userName", "Title"}, schemas, []string{"userName", "Title", "name.middleName"}},
+		{"Default attributes", nil, schemas, []string{
+			"schemas", "id", "externalId", "meta", "userName", "name", "emails",
+			EnterpriseUserURN,
+			"title", "name.middleName",
+		}},
+		{"No request attributes", []string{"userName"}, evaluateSchemas, []string{"userName"}},
+		{"No schemas", nil, nil, nil},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.exp, RequestAttributes(&User{}, test.attributes, test.schemas...))
+		})
+	}
+}
+
+func TestIncludeRequestAttributes(t *testing.T) {
+	schemas, err := json.Marshal([]Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "userName", Type: String, Returned: Always},
+			{Name: "title", Type: String, Returned: Request},
+		},
+	}})
+	require.NoError(t, err)
+
+	var retrieved string
+	var queried []string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			switch req.URL.Path {
+			case "/v2/Schemas":
+				return jsonResponse(200, string(schemas)), nil
+			case "/v2/Users/.search":
+				sr := SearchRequest{}
+				if err := json.NewDecoder(req.Body).Decode(&sr); err == nil {
+					queried = sr.Attributes
+				}
+				return jsonResponse(200, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:ListResponse"],"totalResults":0,"Resources":[]}`), nil
+			}
+			retrieved = req.URL.Query().Get("attributes")
+			return jsonResponse(200, `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"2819c223","userName":"bjensen","title":"Tour Guide"}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/v2", IncludeRequestAttributes(true))
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = c.GetSchemas(ctx)
+	require.NoError(t, err)
+
+	user := User{}
+	require.NoError(t, c.RetrieveResource(ctx, &user, "2819c223"))
+	assert.Equal(t, "schemas,id,externalId,meta,userName,"+EnterpriseUserURN+",title", retrieved)
+	_, err = c.QueryResourceType(ctx, UserResourceType, SearchRequest{Attributes: []string{"userName"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"userName", "title"}, queried)
+
+	c, err = NewClient(hc, "https://example.com/v2")
+	require.NoError(t, err)
+	require.NoError(t, c.RetrieveResource(ctx, &user, "2819c223"))
+	assert.Empty(t, retrieved)
+	_, err = c.QueryResourceType(ctx, UserResourceType, SearchRequest{Attributes: []string{"userName"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"userName"}, queried)
+}
@@ -33,6 +33,7 @@ const (
 	Decimal   Type = "decimal"
 	Integer   Type = "integer"
 	DateTime  Type = "dateTime"
+	Binary    Type = "binary"
 	Reference Type = "reference"
 	Complex   Type = "complex"
 )
@@ -71,7 +72,7 @@ var SchemaResourceType = ResourceType{
 		ID: "ResourceType",
 	},
 	Name:        "Schema",
-	Endpoint:    "Schemas",
+	Endpoint:    "/Schemas",
 	Description: "SCIM Schema - See https://tools.ietf.org/html/rfc7643#section-7",
 	Schema:      SchemaURN,
 }
@@ -0,0 +1,146 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+//
+// Error messages
+//
+
+const (
+	noTenantNameMessage     = "tenant name is required"
+	unknownTenantMessage    = "tenant is not registered"
+	noTenantRegistryMessage = "tenant requested but no TenantRegistry is configured"
+)
+
+// Tenant describes how requests for one tenant of a multi-tenant SCIM
+// server are routed.  Tenants typically share the host of the client's
+// ServiceURL and differ by a path prefix, one or more request headers
+// and, optionally, the credentials used to authenticate.
+type Tenant struct {
+	Name       string       //Name uniquely identifies the tenant within a TenantRegistry.
+	ServiceURL string       //ServiceURL, if provided, replaces the client's ServiceURL for this tenant.
+	PathPrefix string       //PathPrefix is appended to the ServiceURL - e.g. "/tenants/psu" or "tenants/psu".
+	Header     http.Header  //Header contains values that are added to every request made for this tenant.
+	HTTP       *http.Client //HTTP, if provided, replaces the client's http.Client (and therefore its credentials) for this tenant.
+}
+
+// TenantRegistry is a concurrency-safe collection of Tenants keyed by
+// their names.
+type TenantRegistry struct {
+	mu      sync.RWMutex
+	tenants map[string]Tenant
+}
+
+// NewTenantRegistry returns a TenantRegistry containing the provided
+// Tenants.
+func NewTenantRegistry(tenants ...Tenant) (*TenantRegistry, error) {
+	tr := &TenantRegistry{
+		tenants: make(map[string]Tenant),
+	}
+	err := tr.Register(tenants...)
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// Lookup returns the Tenant associated with the name parameter as well as
+// a boolean that indicates whether the registry contained the requested
+// Tenant.
+func (tr *TenantRegistry) Lookup(name string) (Tenant, bool) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	t, ok := tr.tenants[name]
+	return t, ok
+}
+
+// Register adds (or replaces) one or more Tenants in the registry.
+func (tr *TenantRegistry) Register(tenants ...Tenant) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for _, t := range tenants {
+		if t.Name == "" {
+			return errors.New(noTenantNameMessage)
+		}
+		tr.tenants[t.Name] = t
+	}
+	return nil
+}
+
+// Remove deletes the Tenant with the provided name from the registry.
+func (tr *TenantRegistry) Remove(name string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	delete(tr.tenants, name)
+}
+
+//
+// Tenant context
+//
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of the provided context that routes SCIM
+// requests made with it to the named Tenant.
+func WithTenant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, name)
+}
+
+// TenantFromContext returns the name of the Tenant stored in the provided
+// context by WithTenant as well as a boolean indicating whether a tenant
+// was present.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(tenantKey{}).(string)
+	return name, ok
+}
+
+//
+// Tenant resolution
+//
+
+// tenant returns the Tenant that the provided context's requests should
+// be routed to, or nil if the request should use the client's own
+// configuration.
+func (c Client) tenant(ctx context.Context) (*Tenant, error) {
+	name, ok := TenantFromContext(ctx)
+	if !ok && c.cfg != nil {
+		name = c.cfg.DefaultTenant
+	}
+	if name == "" {
+		return nil, nil
+	}
+	if c.cfg == nil || c.cfg.Tenants == nil {
+		return nil, fmt.Errorf("%s: %s", noTenantRegistryMessage, name)
+	}
+	t, ok := c.cfg.Tenants.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", unknownTenantMessage, name)
+	}
+	return &t, nil
+}
+
+// serviceURL returns the base URI of the SCIM server's resources for the
+// tenant (if any) associated with the provided context.  The tenant's
+// PathPrefix is joined to the URL whether or not it has leading and
+// trailing slashes.
+func (c Client) serviceURL(ctx context.Context) (string, error) {
+	t, err := c.tenant(ctx)
+	if err != nil || t == nil {
+		return c.cfg.ServiceURL, err
+	}
+	url := c.cfg.ServiceURL
+	if t.ServiceURL != "" {
+		url = strings.TrimSuffix(t.ServiceURL, "/")
+	}
+	if prefix := strings.Trim(t.PathPrefix, "/"); prefix != "" {
+		url += "/" + prefix
+	}
+	return url, nil
+}
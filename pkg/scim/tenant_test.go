@@ -0,0 +1,110 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantRegistry(t *testing.T) {
+	tr, err := NewTenantRegistry(Tenant{Name: "psu"}, Tenant{Name: "example"})
+	require.NoError(t, err)
+
+	_, ok := tr.Lookup("psu")
+	assert.True(t, ok)
+	tr.Remove("psu")
+	_, ok = tr.Lookup("psu")
+	assert.False(t, ok)
+
+	assert.EqualError(t, tr.Register(Tenant{}), noTenantNameMessage)
+}
+
+func TestTenantRouting(t *testing.T) {
+	const minuser = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"2819c223","userName":"bjensen"}`
+
+	var tenantReq *http.Request
+	tenantHTTP := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			tenantReq = req
+			return jsonResponse(200, minuser), nil
+		}),
+	}
+
+	var defaultReq *http.Request
+	defaultHTTP := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			defaultReq = req
+			return jsonResponse(200, minuser), nil
+		}),
+	}
+
+	tr, err := NewTenantRegistry(
+		Tenant{
+			Name:       "psu",
+			PathPrefix: "/tenants/psu/",
+			Header:     http.Header{"X-Tenant": []string{"psu"}},
+			HTTP:       tenantHTTP,
+		},
+		Tenant{
+			Name:       "example",
+			ServiceURL: "https://scim.example.org/v2/",
+			Header:     http.Header{"X-Tenant": []string{"example"}},
+		},
+		Tenant{
+			Name:       "relative",
+			ServiceURL: "https://scim.example.net",
+			PathPrefix: "v2",
+			Header:     http.Header{"X-Tenant": []string{"relative"}},
+		},
+	)
+	require.NoError(t, err)
+
+	c, err := NewClient(defaultHTTP, "https://example.com/scim", Tenants(tr))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		req    **http.Request
+		url    string
+		header string
+	}{
+		{"No tenant", context.Background(), &defaultReq, "https://example.com/scim/Users/2819c223", ""},
+		{"Tenant with path prefix and credentials", WithTenant(context.Background(), "psu"), &tenantReq, "https://example.com/scim/tenants/psu/Users/2819c223", "psu"},
+		{"Tenant with service URL", WithTenant(context.Background(), "example"), &defaultReq, "https://scim.example.org/v2/Users/2819c223", "example"},
+		{"Tenant with path prefix without a leading slash", WithTenant(context.Background(), "relative"), &defaultReq, "https://scim.example.net/v2/Users/2819c223", "relative"},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			tenantReq, defaultReq = nil, nil
+			user := User{}
+			require.NoError(t, c.RetrieveResource(test.ctx, &user, "2819c223"))
+			req := *test.req
+			require.NotNil(t, req)
+			assert.Equal(t, test.url, req.URL.String())
+			assert.Equal(t, test.header, req.Header.Get("X-Tenant"))
+			assert.Equal(t, "bjensen", user.UserName)
+		})
+	}
+}
+
+func TestUnknownTenant(t *testing.T) {
+	tr, err := NewTenantRegistry()
+	require.NoError(t, err)
+
+	c, err := NewClient(nil, "https://example.com/scim", Tenants(tr), DefaultTenant("missing"))
+	require.NoError(t, err)
+	user := User{}
+	err = c.RetrieveResource(context.Background(), &user, "2819c223")
+	assert.EqualError(t, err, unknownTenantMessage+": missing")
+
+	c, err = NewClient(nil, "https://example.com/scim")
+	require.NoError(t, err)
+	err = c.RetrieveResource(WithTenant(context.Background(), "psu"), &user, "2819c223")
+	assert.EqualError(t, err, noTenantRegistryMessage+": psu")
+}
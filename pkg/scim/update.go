@@ -0,0 +1,121 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/PennState/httputil/pkg/httperror"
+	log "github.com/sirupsen/logrus"
+)
+
+const noMutatorMessage = "Update requires a Mutator"
+
+// Mutator changes the local representation of a resource before it is
+// written back to the SCIM server by Update.
+type Mutator func(Resource) error
+
+// RetriesExhaustedError is returned by Update when the resource was
+// modified by another client on every attempt.
+type RetriesExhaustedError struct {
+	ID       string
+	Attempts int
+	Err      error
+}
+
+func (ree RetriesExhaustedError) Error() string {
+	return fmt.Sprintf("resource %s was modified concurrently on each of %d attempts: %v", ree.ID, ree.Attempts, ree.Err)
+}
+
+// UpdateOpt changes the behavior of Update.
+type UpdateOpt func(*updateCfg)
+
+type updateCfg struct {
+	patch bool
+}
+
+// UpdateWithPatch causes Update to send the PATCH operations that
+// transform the retrieved resource into the mutated one (see Diff) rather
+// than replacing the resource.  Nothing is sent if the mutator doesn't
+// change the resource.
+func UpdateWithPatch() UpdateOpt {
+	return func(cfg *updateCfg) {
+		cfg.patch = true
+	}
+}
+
+// Update performs an optimistic-concurrency update of the resource with
+// the provided id.  The resource is retrieved into res, changed by the
+// mutate function and then replaced (or, with UpdateWithPatch, modified)
+// on the SCIM server using the retrieved version as the If-Match
+// precondition.  If the server reports that the precondition failed, the
+// process is repeated up to the client's MaxUpdateRetries.
+func (c Client) Update(ctx context.Context, res Resource, id string, mutate Mutator, opts ...UpdateOpt) error {
+	if mutate == nil {
+		return errors.New(noMutatorMessage)
+	}
+	cfg := updateCfg{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var err error
+	attempts := c.cfg.MaxUpdateRetries + 1
+	for attempt := 1; attempt <= attempts; attempt++ {
+		reset(res)
+		err = c.RetrieveResource(ctx, res, id)
+		if err != nil {
+			return err
+		}
+		var retrieved Resource
+		if cfg.patch {
+			retrieved, err = copyResource(res, res)
+			if err != nil {
+				return err
+			}
+		}
+		err = mutate(res)
+		if err != nil {
+			return err
+		}
+		if cfg.patch {
+			_, err = c.patch(ctx, retrieved, res)
+		} else {
+			err = c.ReplaceResource(ctx, res)
+		}
+		if !isPreconditionFailed(err) {
+			return err
+		}
+		log.Debugf("Precondition failed updating %s (attempt %d of %d)", id, attempt, attempts)
+	}
+	return RetriesExhaustedError{
+		ID:       id,
+		Attempts: attempts,
+		Err:      err,
+	}
+}
+
+// reset returns the resource to its zero value so that it can be
+// repopulated by the SCIM server's current representation.
+func reset(res Resource) {
+	v := reflect.ValueOf(res)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
+
+// isPreconditionFailed indicates whether the error was caused by the
+// SCIM server rejecting a request's If-Match header.
+// https://tools.ietf.org/html/rfc7644#section-3.14
+func isPreconditionFailed(err error) bool {
+	switch e := err.(type) {
+	case ErrorResponse:
+		status, _ := strconv.Atoi(e.Status)
+		return status == http.StatusPreconditionFailed
+	case httperror.HTTPError:
+		return e.Code == http.StatusPreconditionFailed
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	const (
+		user    = `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"2819c223","userName":"bjensen","meta":{"version":"W/\"%d\""}}`
+		failure = `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"status":"412","detail":"version mismatch"}`
+	)
+
+	tests := []struct {
+		name     string
+		opts     []UpdateOpt
+		method   string
+		failures int
+		puts     int
+		err      bool
+	}{
+		{"No conflict", nil, "PUT", 0, 1, false},
+		{"Conflict then success", nil, "PUT", 2, 3, false},
+		{"Retries exhausted", nil, "PUT", 10, 4, true},
+		{"PATCH without conflict", []UpdateOpt{UpdateWithPatch()}, "PATCH", 0, 1, false},
+		{"PATCH conflict then success", []UpdateOpt{UpdateWithPatch()}, "PATCH", 2, 3, false},
+		{"PATCH retries exhausted", []UpdateOpt{UpdateWithPatch()}, "PATCH", 10, 4, true},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			version := 0
+			puts := 0
+			var ifMatch []string
+			hc := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if req.Method == "GET" {
+						version++
+						return jsonResponse(200, fmt.Sprintf(user, version)), nil
+					}
+					puts++
+					ifMatch = append(ifMatch, req.Header.Get("If-Match"))
+					assert.Equal(t, test.method, req.Method)
+					if puts <= test.failures {
+						return jsonResponse(412, failure), nil
+					}
+					body, _ := ioutil.ReadAll(req.Body)
+					if req.Method == "PATCH" {
+						assert.JSONEq(t, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"add","path":"displayName","value":"Babs Jensen"}]}`, string(body))
+						return &http.Response{StatusCode: http.StatusNoContent, Header: http.Header{}, Body: http.NoBody}, nil
+					}
+					return jsonResponse(200, string(body)), nil
+				}),
+			}
+			c, err := NewClient(hc, "https://example.com/scim")
+			require.NoError(t, err)
+
+			user := User{}
+			mutations := 0
+			err = c.Update(context.Background(), &user, "2819c223", func(res Resource) error {
+				mutations++
+				res.(*User).DisplayName = "Babs Jensen"
+				return nil
+			}, test.opts...)
+			assert.Equal(t, test.puts, puts)
+			assert.Equal(t, test.puts, mutations)
+			for i, im := range ifMatch {
+				assert.Equal(t, fmt.Sprintf("W/\"%d\"", i+1), im)
+			}
+			if test.err {
+				assert.IsType(t, RetriesExhaustedError{}, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Babs Jensen", user.DisplayName)
+		})
+	}
+}
+
+func TestUpdateMutatorError(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "GET", req.Method)
+			return jsonResponse(200, `{"id":"2819c223","userName":"bjensen"}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim", MaxUpdateRetries(1))
+	require.NoError(t, err)
+
+	exp := errors.New("mutation failed")
+	err = c.Update(context.Background(), &User{}, "2819c223", func(Resource) error {
+		return exp
+	})
+	assert.Equal(t, exp, err)
+}
+
+func TestUpdateWithPatchUnchanged(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != "GET" {
+				return nil, errors.New("unexpected request")
+			}
+			return jsonResponse(200, `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"2819c223","userName":"bjensen"}`), nil
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+
+	user := User{}
+	err = c.Update(context.Background(), &user, "2819c223", func(res Resource) error {
+		res.(*User).UserName = "bjensen"
+		return nil
+	}, UpdateWithPatch())
+	require.NoError(t, err)
+	assert.Equal(t, "bjensen", user.UserName)
+}
+
+func TestUpdateWithoutMutator(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("unexpected request")
+		}),
+	}
+	c, err := NewClient(hc, "https://example.com/scim")
+	require.NoError(t, err)
+
+	err = c.Update(context.Background(), &User{}, "2819c223", nil)
+	assert.EqualError(t, err, noMutatorMessage)
+}
+
+func TestNegativeMaxUpdateRetries(t *testing.T) {
+	_, err := NewClient(nil, "https://example.com/scim", MaxUpdateRetries(-1))
+	assert.EqualError(t, err, negativeRetriesMessage)
+}
@@ -92,7 +92,10 @@ var UserResourceType = ResourceType{
 	Name:        "User",
 	Endpoint:    "/Users",
 	Description: "SCIM ResourceType - See https://tools.ietf.org/html/rfc7643#section-6",
-	Schema:      ResourceTypeURN,
+	Schema:      UserURN,
+	SchemaExtensions: []SchemaExtension{
+		{Schema: EnterpriseUserURN},
+	},
 }
 
 //URN returns the IANA registered SCIM name for the User data structure
@@ -115,14 +118,14 @@ func (u User) ResourceType() ResourceType {
 
 // MarshalJSON implements https://golang.org/pkg/encoding/json/#Marshaler
 func (u User) MarshalJSON() ([]byte, error) {
-	type Alias User
+	type userAlias User
 	json := ap.ConfigCompatibleWithStandardLibrary
-	return json.Marshal((Alias)(u))
+	return json.Marshal((userAlias)(u))
 }
 
 // UnmarshalJSON implements https://golang.org/pkg/encoding/json/#Unmarshaler
 func (u *User) UnmarshalJSON(data []byte) error {
-	type Alias User
+	type userAlias User
 	json := ap.ConfigCompatibleWithStandardLibrary
-	return json.Unmarshal(data, (*Alias)(u))
+	return json.Unmarshal(data, (*userAlias)(u))
 }
@@ -0,0 +1,204 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InvalidResourceError is returned when a resource doesn't conform to its
+// schemas.  Every problem found is included in the Diagnostics.
+type InvalidResourceError struct {
+	ResourceType string
+	Diagnostics  []Diagnostic
+}
+
+func (ire InvalidResourceError) Error() string {
+	msgs := make([]string, len(ire.Diagnostics))
+	for i, d := range ire.Diagnostics {
+		msgs[i] = d.String()
+	}
+	return fmt.Sprintf("invalid %s resource - %s", ire.ResourceType, strings.Join(msgs, "; "))
+}
+
+// Validate checks the resource against its core schema and the schema
+// extensions of its ResourceType using the provided schemas:
+//
+//   - required attributes (and sub-attributes) must be present unless
+//     they're readOnly and therefore assigned by the server.
+//   - values must be of the attribute's type (e.g. integers must not have
+//     fractions and dateTimes must be xsd:dateTimes).
+//   - string values of attributes with canonicalValues must be one of
+//     them.
+//   - multi-valued attributes must be arrays, singular attributes must not.
+//   - at most one value of a multi-valued attribute may be primary.
+//   - the resource's schemas must include its core schema and each
+//     extension it contains, required schema extensions must be present and
+//     no other extensions may be present.
+//
+// The core schemas are used if no schemas are provided.  Attributes of
+// schemas that aren't provided aren't checked.  Every violation is
+// reported, using the attribute's path (with the index of the offending
+// value of a multi-valued attribute - e.g. emails[1].type), in an
+// InvalidResourceError.
+func Validate(res Resource, schemas ...Schema) error {
+	doc, err := document(res)
+	if err != nil {
+		return err
+	}
+	if len(schemas) == 0 {
+		schemas = CoreSchemas()
+	}
+	v := validator{evaluator: newEvaluator(res, schemas), diags: []Diagnostic{}}
+	v.resource(doc)
+	if len(v.diags) > 0 {
+		return InvalidResourceError{ResourceType: res.ResourceType().Name, Diagnostics: v.diags}
+	}
+	return nil
+}
+
+type validator struct {
+	evaluator
+	diags []Diagnostic
+}
+
+func (v *validator) resource(doc map[string]interface{}) {
+	listed := map[string]bool{}
+	schemas, _ := lookup(doc, "schemas").([]interface{})
+	for _, s := range schemas {
+		if str, ok := s.(string); ok {
+			listed[strings.ToLower(str)] = true
+		}
+	}
+	if !listed[strings.ToLower(v.urn)] {
+		v.report("schemas", "must include the core schema %s", v.urn)
+	}
+	if v.hasSchema(v.urn) {
+		v.object("", doc, v.root(doc).attrs)
+	}
+
+	for _, se := range v.extensions {
+		ext := lookup(doc, se.Schema)
+		if isEmpty(ext) {
+			if se.Required {
+				v.report(se.Schema, "required schema extension is missing")
+			}
+			continue
+		}
+		if !listed[strings.ToLower(se.Schema)] {
+			v.report("schemas", "must include the schema extension %s", se.Schema)
+		}
+		obj, ok := ext.(map[string]interface{})
+		if !ok {
+			v.report(se.Schema, "schema extension must be an object")
+			continue
+		}
+		if v.hasSchema(se.Schema) {
+			v.object(se.Schema+":", obj, v.schemas[strings.ToLower(se.Schema)].Attributes)
+		}
+	}
+
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasPrefix(strings.ToLower(k), "urn:") && !v.isExtension(k) && !strings.EqualFold(k, v.urn) {
+			v.report(k, "is not a schema extension of the resource type")
+		}
+	}
+}
+
+// object checks the attributes of a resource, extension or complex value -
+// prefix is prepended to each attribute's name to form its path.
+func (v *validator) object(prefix string, obj map[string]interface{}, attrs []Attribute) {
+	for idx := range attrs {
+		attr := &attrs[idx]
+		path := prefix + attr.Name
+		value := lookup(obj, attr.Name)
+		if isEmpty(value) {
+			if attr.Required && attr.Mutability != ReadOnly {
+				v.report(path, "required attribute is missing")
+			}
+			continue
+		}
+		v.attribute(path, attr, value)
+	}
+}
+
+func (v *validator) attribute(path string, attr *Attribute, value interface{}) {
+	arr, isArr := value.([]interface{})
+	switch {
+	case !attr.Multivalued && isArr:
+		v.report(path, "single-valued attribute must not be an array")
+		return
+	case !attr.Multivalued:
+		v.value(path, attr, value)
+		return
+	case !isArr:
+		v.report(path, "multi-valued attribute must be an array")
+		return
+	}
+
+	primaries := 0
+	for idx, e := range arr {
+		v.value(fmt.Sprintf("%s[%d]", path, idx), attr, e)
+		if obj, ok := e.(map[string]interface{}); ok && lookup(obj, "primary") == true {
+			primaries++
+		}
+	}
+	if primaries > 1 {
+		v.report(path, "only one value may be primary but %d are", primaries)
+	}
+}
+
+// value checks a single value of an attribute.
+// https://tools.ietf.org/html/rfc7643#section-2.3
+func (v *validator) value(path string, attr *Attribute, value interface{}) {
+	ok := true
+	switch attr.Type {
+	case String, Reference, Binary:
+		_, ok = value.(string)
+	case Boolean:
+		_, ok = value.(bool)
+	case Decimal:
+		_, ok = value.(json.Number)
+	case Integer:
+		n, isNum := value.(json.Number)
+		_, err := n.Int64()
+		ok = isNum && err == nil
+	case DateTime:
+		s, isStr := value.(string)
+		_, err := time.Parse(time.RFC3339Nano, s)
+		ok = isStr && err == nil
+	case Complex:
+		obj, isObj := value.(map[string]interface{})
+		if isObj {
+			v.object(path+".", obj, attr.SubAttributes)
+		}
+		ok = isObj
+	}
+	if !ok {
+		b, _ := json.Marshal(value)
+		v.report(path, "%s is not a valid %s", b, attr.Type)
+		return
+	}
+
+	s, isStr := value.(string)
+	if !isStr || len(attr.CanonicalValues) == 0 {
+		return
+	}
+	for _, cv := range attr.CanonicalValues {
+		if s == cv || (!attr.CaseExact && strings.EqualFold(s, cv)) {
+			return
+		}
+	}
+	v.report(path, "%q is not one of the canonical values (%s)", s, strings.Join(attr.CanonicalValues, ", "))
+}
+
+func (v *validator) report(path string, format string, a ...interface{}) {
+	v.diags = append(v.diags, Diagnostic{Path: path, Msg: fmt.Sprintf(format, a...)})
+}
@@ -0,0 +1,184 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals
+var validateSchemas = []Schema{
+	{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "userName", Type: String, Required: true},
+			{Name: "title", Type: String},
+			{Name: "active", Type: Boolean},
+			{Name: "name", Type: Complex, SubAttributes: []Attribute{
+				{Name: "familyName", Type: String, Required: true},
+				{Name: "givenName", Type: String},
+			}},
+			{Name: "emails", Type: Complex, Multivalued: true, SubAttributes: []Attribute{
+				{Name: "value", Type: String},
+				{Name: "type", Type: String, CanonicalValues: []string{"work", "home", "other"}},
+				{Name: "primary", Type: Boolean},
+			}},
+			{Name: "groups", Type: Complex, Multivalued: true, Mutability: ReadOnly, Required: true},
+		},
+	},
+	{
+		CommonAttributes: CommonAttributes{ID: EnterpriseUserURN},
+		Attributes: []Attribute{
+			{Name: "employeeNumber", Type: String},
+			{Name: "costCenter", Type: String},
+			{Name: "manager", Type: Complex, SubAttributes: []Attribute{
+				{Name: "value", Type: String},
+			}},
+		},
+	},
+}
+
+// employee is a User whose ResourceType requires the enterprise extension.
+type employee struct {
+	User
+}
+
+func (e employee) ResourceType() ResourceType {
+	rt := UserResourceType
+	rt.Name = "Employee"
+	rt.SchemaExtensions = []SchemaExtension{{Schema: EnterpriseUserURN, Required: true}}
+	return rt
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*User)
+		exp    []Diagnostic
+	}{
+		{"Valid", func(u *User) {}, nil},
+		{"Required", func(u *User) {
+			u.UserName = ""
+			u.Name.FamilyName = ""
+		}, []Diagnostic{
+			{"userName", "required attribute is missing"},
+			{"name.familyName", "required attribute is missing"},
+		}},
+		{"Canonical values", func(u *User) {
+			u.Emails[1].Type = "Home"
+			u.Emails = append(u.Emails, Email{Value: "babs@example.org", Multivalued: Multivalued{Type: "office"}})
+		}, []Diagnostic{
+			{"emails[2].type", `"office" is not one of the canonical values (work, home, other)`},
+		}},
+		{"Single primary", func(u *User) {
+			u.Emails[1].Primary = true
+		}, []Diagnostic{
+			{"emails", "only one value may be primary but 2 are"},
+		}},
+		{"Schemas", func(u *User) {
+			u.Schemas = nil
+		}, []Diagnostic{
+			{"schemas", "must include the core schema " + UserURN},
+			{"schemas", "must include the schema extension " + EnterpriseUserURN},
+		}},
+		{"Extension types", func(u *User) {
+			u.AdditionalProperties[EnterpriseUserURN] = json.RawMessage(`{"employeeNumber":701984,"costCenter":true,"manager":["26118915"]}`)
+		}, []Diagnostic{
+			{EnterpriseUserURN + ":employeeNumber", "701984 is not a valid string"},
+			{EnterpriseUserURN + ":costCenter", "true is not a valid string"},
+			{EnterpriseUserURN + ":manager", "single-valued attribute must not be an array"},
+		}},
+		{"Unknown extension", func(u *User) {
+			u.AdditionalProperties["urn:example:params:scim:schemas:extension:2.0:Badge"] = json.RawMessage(`{"number":"42"}`)
+		}, []Diagnostic{
+			{"urn:example:params:scim:schemas:extension:2.0:Badge", "is not a schema extension of the resource type"},
+		}},
+	}
+
+	for idx := range tests {
+		test := tests[idx]
+		t.Run(test.name, func(t *testing.T) {
+			u := User{}
+			require.NoError(t, json.Unmarshal([]byte(evaluateUser), &u))
+			test.mutate(&u)
+
+			err := Validate(&u, validateSchemas...)
+			if test.exp == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.IsType(t, InvalidResourceError{}, err)
+			assert.Equal(t, "User", err.(InvalidResourceError).ResourceType)
+			assert.Equal(t, test.exp, err.(InvalidResourceError).Diagnostics)
+		})
+	}
+}
+
+func TestValidateTypes(t *testing.T) {
+	schemas := []Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "title", Type: Integer},
+			{Name: "active", Type: String},
+			{Name: "emails", Type: Complex},
+			{Name: "name", Type: Complex, Multivalued: true},
+			{Name: "userType", Type: DateTime},
+		},
+	}}
+	u := User{}
+	require.NoError(t, json.Unmarshal([]byte(evaluateUser), &u))
+
+	err := Validate(&u, schemas...)
+	require.IsType(t, InvalidResourceError{}, err)
+	assert.Equal(t, []Diagnostic{
+		{"title", `"Tour Guide" is not a valid integer`},
+		{"active", "true is not a valid string"},
+		{"emails", "single-valued attribute must not be an array"},
+		{"name", "multi-valued attribute must be an array"},
+		{"userType", `"Employee" is not a valid dateTime`},
+	}, err.(InvalidResourceError).Diagnostics)
+}
+
+func TestValidateBinary(t *testing.T) {
+	schemas := []Schema{{
+		CommonAttributes: CommonAttributes{ID: UserURN},
+		Attributes: []Attribute{
+			{Name: "title", Type: Binary},
+			{Name: "active", Type: Binary},
+		},
+	}}
+	u := User{
+		CommonAttributes: CommonAttributes{Schemas: []string{UserURN}},
+		Title:            "TUlJRE1EQ0NBcDJnQXdJQkFnSUJBREFO",
+		Active:           true,
+	}
+
+	err := Validate(&u, schemas...)
+	require.IsType(t, InvalidResourceError{}, err)
+	assert.Equal(t, []Diagnostic{
+		{"active", "true is not a valid binary"},
+	}, err.(InvalidResourceError).Diagnostics)
+}
+
+func TestValidateRequiredExtension(t *testing.T) {
+	e := employee{User: User{
+		CommonAttributes: CommonAttributes{Schemas: []string{UserURN}},
+		UserName:         "bjensen@example.com",
+	}}
+	err := Validate(&e, validateSchemas...)
+	assert.EqualError(t, err, "invalid Employee resource - "+EnterpriseUserURN+": required schema extension is missing")
+}
+
+func TestValidateWithoutSchemas(t *testing.T) {
+	u := User{CommonAttributes: CommonAttributes{Schemas: []string{UserURN}}}
+	err := Validate(&u)
+	require.IsType(t, InvalidResourceError{}, err)
+	assert.Equal(t, []Diagnostic{
+		{"userName", "required attribute is missing"},
+	}, err.(InvalidResourceError).Diagnostics)
+
+	u.UserName = "bjensen@example.com"
+	assert.NoError(t, Validate(&u))
+}
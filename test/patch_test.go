@@ -0,0 +1,103 @@
+package blackbox
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/PennState/proctor/pkg/goldenfile"
+	"github.com/PennState/scim-client/pkg/scim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals
+var patchOp = scim.NewPatchOp(
+	scim.PatchOperation{Op: scim.PatchReplace, Path: "active", Value: false},
+	scim.PatchOperation{Op: scim.PatchReplace, Path: "name.givenName", Value: "Babs"},
+	scim.PatchOperation{Op: scim.PatchReplace, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Value: "Tour Operations"},
+	scim.PatchOperation{Op: scim.PatchAdd, Path: "emails", Value: []interface{}{
+		map[string]interface{}{"value": "babs@example.org", "type": "home", "primary": true},
+	}},
+	scim.PatchOperation{Op: scim.PatchReplace, Path: `emails[type eq "work"].primary`, Value: false},
+	scim.PatchOperation{Op: scim.PatchRemove, Path: `emails[value eq "babs@jensen.org"]`},
+	scim.PatchOperation{Op: scim.PatchReplace, Path: "title", Value: "Senior Tour Guide"},
+)
+
+//nolint:gochecknoglobals
+var dialects = []struct {
+	Name       string
+	GoldenFile string
+	Dialect    scim.Dialect
+}{
+	{"Standard", "patchop_standard.json", scim.StandardDialect},
+	{"Entra ID", "patchop_entraid.json", scim.EntraIDDialect},
+}
+
+func TestPatchDialectEncoding(t *testing.T) {
+	for idx := range dialects {
+		d := dialects[idx]
+		t.Run(d.Name, func(t *testing.T) {
+			po, err := d.Dialect.Encode(patchOp)
+			require.NoError(t, err)
+			data, err := json.Marshal(po)
+			require.NoError(t, err)
+			fp := goldenfile.GetDefaultFilePath(d.GoldenFile)
+			goldenfile.AssertJSONEq(t, fp, string(data))
+		})
+	}
+}
+
+func TestPatchDialectDecoding(t *testing.T) {
+	exp, err := json.Marshal(patchOp)
+	require.NoError(t, err)
+
+	for idx := range dialects {
+		d := dialects[idx]
+		t.Run(d.Name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(goldenfile.GetDefaultFilePath(d.GoldenFile))
+			require.NoError(t, err)
+			c, err := scim.NewClient(nil, "https://example.com/v2", scim.PatchDialect(d.Dialect))
+			require.NoError(t, err)
+
+			po, err := c.ParsePatchOp(context.Background(), data)
+			require.NoError(t, err)
+			act, err := json.Marshal(po)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(exp), string(act))
+		})
+	}
+}
+
+func TestEntraIDDecodingOnlyConvertsBooleans(t *testing.T) {
+	po := scim.NewPatchOp(
+		scim.PatchOperation{Op: "Replace", Value: map[string]interface{}{
+			"active":   "True",
+			"nickName": "True",
+			"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{
+				"department": "False",
+			},
+		}},
+		scim.PatchOperation{Op: "Add", Path: "emails", Value: []interface{}{
+			map[string]interface{}{"value": "True", "primary": "True"},
+		}},
+		scim.PatchOperation{Op: "Replace", Path: "title", Value: "False"},
+		scim.PatchOperation{Op: "Replace", Path: "urn:example:unknown:flag", Value: "True"},
+	)
+
+	act, err := scim.EntraIDDialect.Decode(po)
+	require.NoError(t, err)
+	assert.Equal(t, []scim.PatchOperation{
+		{Op: scim.PatchReplace, Path: "active", Value: true},
+		{Op: scim.PatchReplace, Path: "nickName", Value: "True"},
+		{Op: scim.PatchReplace, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User", Value: map[string]interface{}{
+			"department": "False",
+		}},
+		{Op: scim.PatchAdd, Path: "emails", Value: []interface{}{
+			map[string]interface{}{"value": "True", "primary": true},
+		}},
+		{Op: scim.PatchReplace, Path: "title", Value: "False"},
+		{Op: scim.PatchReplace, Path: "urn:example:unknown:flag", Value: "True"},
+	}, act.Operations)
+}